@@ -0,0 +1,114 @@
+// Command course exports and imports course bundles,
+// emails enrolled users about released contents, notify-release should be run daily,
+// and rebuilds courses' search index, reindex should be run once after migrate the search columns
+//
+//	course export <course-id> <file.zip>
+//	course import <owner-user-id> <file.zip>
+//	course notify-release
+//	course reindex
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/acoshift/pgsql/pgctx"
+	_ "github.com/lib/pq"
+
+	"github.com/acoshift/acourse/internal/pkg/bundle"
+	"github.com/acoshift/acourse/internal/pkg/config"
+	"github.com/acoshift/acourse/internal/pkg/course"
+	"github.com/acoshift/acourse/internal/pkg/email"
+	"github.com/acoshift/acourse/internal/pkg/file"
+)
+
+func main() {
+	if len(os.Args) != 4 && !(len(os.Args) == 2 && (os.Args[1] == "notify-release" || os.Args[1] == "reindex")) {
+		fmt.Fprintln(os.Stderr, "usage:")
+		fmt.Fprintln(os.Stderr, "  course export <course-id> <file.zip>")
+		fmt.Fprintln(os.Stderr, "  course import <owner-user-id> <file.zip>")
+		fmt.Fprintln(os.Stderr, "  course notify-release")
+		fmt.Fprintln(os.Stderr, "  course reindex")
+		os.Exit(2)
+	}
+
+	config.Init()
+	defer config.Close()
+
+	email.Init()
+	file.Init()
+
+	ctx := pgctx.NewContext(context.Background(), config.DBClient())
+
+	var err error
+	switch os.Args[1] {
+	case "export":
+		err = exportCourse(ctx, os.Args[2], os.Args[3])
+	case "import":
+		err = importCourse(ctx, os.Args[2], os.Args[3])
+	case "notify-release":
+		err = notifyRelease(ctx)
+	case "reindex":
+		err = course.RebuildSearchIndex(ctx)
+	default:
+		err = fmt.Errorf("unknown command %s", os.Args[1])
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func exportCourse(ctx context.Context, courseID, filename string) error {
+	fp, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+
+	err = bundle.Export(ctx, fp, courseID)
+	if err != nil {
+		return err
+	}
+	return fp.Close()
+}
+
+func importCourse(ctx context.Context, ownerID, filename string) error {
+	fp, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+
+	st, err := fp.Stat()
+	if err != nil {
+		return err
+	}
+
+	x, err := bundle.Read(fp, st.Size())
+	if err != nil {
+		return err
+	}
+
+	r, err := bundle.Import(ctx, x, ownerID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("imported course", r.CourseID)
+	for _, c := range r.Conflicts {
+		fmt.Println("conflict:", c)
+	}
+	return nil
+}
+
+func notifyRelease(ctx context.Context) error {
+	n, err := course.NotifyReleasedContents(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("sent", n, "emails")
+	return nil
+}
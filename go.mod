@@ -19,6 +19,7 @@ require (
 	github.com/disintegration/imaging v1.6.2
 	github.com/dustin/go-humanize v1.0.0
 	github.com/go-redis/redis/v8 v8.4.0
+	github.com/jung-kurt/gofpdf v1.16.2
 	github.com/lib/pq v1.10.6
 	github.com/microcosm-cc/bluemonday v1.0.19
 	github.com/moonrhythm/hime v1.1.3-0.20220721223656-cfa37c6497e6
@@ -0,0 +1,55 @@
+package admin
+
+import (
+	"github.com/moonrhythm/hime"
+
+	"github.com/acoshift/acourse/internal/app/view"
+	"github.com/acoshift/acourse/internal/pkg/context/appctx"
+	"github.com/acoshift/acourse/internal/pkg/course"
+)
+
+func getCategories(ctx *hime.Context) error {
+	list, err := course.GetCategories(ctx)
+	if err != nil {
+		return err
+	}
+
+	p := view.Page(ctx)
+	p.Data["Navbar"] = "admin.categories"
+	p.Data["Categories"] = list
+	return ctx.View("admin.categories", p)
+}
+
+func postCategories(ctx *hime.Context) error {
+	f := appctx.GetFlash(ctx)
+
+	var err error
+	switch ctx.FormValue("action") {
+	case "create":
+		_, err = course.CreateCategory(ctx, &course.CreateCategoryArgs{
+			Slug: ctx.PostFormValueTrimSpace("slug"),
+			Name: ctx.PostFormValueTrimSpace("name"),
+		})
+	case "update":
+		err = course.UpdateCategory(ctx, &course.UpdateCategoryArgs{
+			ID:   ctx.PostFormValue("id"),
+			Slug: ctx.PostFormValueTrimSpace("slug"),
+			Name: ctx.PostFormValueTrimSpace("name"),
+		})
+	case "delete":
+		err = course.DeleteCategory(ctx, ctx.PostFormValue("id"))
+	}
+	if err == course.ErrInvalidCategory {
+		f.Add("Errors", "name is required and slug must contain only a-z, 0-9 and -")
+		return ctx.RedirectToGet()
+	}
+	if err == course.ErrCategorySlugNotAvailable {
+		f.Add("Errors", "slug is already used by another category")
+		return ctx.RedirectToGet()
+	}
+	if err != nil {
+		return err
+	}
+
+	return ctx.RedirectToGet()
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/acoshift/acourse/internal/app/view"
 	"github.com/acoshift/acourse/internal/pkg/admin"
+	"github.com/acoshift/acourse/internal/pkg/context/appctx"
+	"github.com/acoshift/acourse/internal/pkg/course"
 )
 
 func getCourses(ctx *hime.Context) error {
@@ -27,6 +29,56 @@ func getCourses(ctx *hime.Context) error {
 	p := view.Page(ctx)
 	p.Data["Navbar"] = "admin.courses"
 	p.Data["Courses"] = list
-	p.Data["Paginate"] = pn
+	p.Data["Paginate"] = view.Paginate(ctx, pn)
 	return ctx.View("admin.courses", p)
 }
+
+func postCourses(ctx *hime.Context) error {
+	id := ctx.PostFormValue("id")
+	value := ctx.PostFormValue("value") == "1"
+
+	c, err := course.Get(ctx, id)
+	if err == course.ErrNotFound {
+		return ctx.RedirectToGet()
+	}
+	if err != nil {
+		return err
+	}
+
+	opt := c.Option
+	switch ctx.FormValue("action") {
+	case "public":
+		opt.Public = value
+	case "enroll":
+		opt.Enroll = value
+	case "archive":
+		if value {
+			err = course.Archive(ctx, id)
+		} else {
+			err = course.Unarchive(ctx, id)
+		}
+		if err != nil {
+			return err
+		}
+		return ctx.RedirectToGet()
+	case "delete":
+		err = course.Delete(ctx, id)
+		if err == course.ErrHasEnrolls {
+			appctx.GetFlash(ctx).Add("Errors", "course has enrolls or payments, archive it instead")
+			return ctx.RedirectToGet()
+		}
+		if err != nil && err != course.ErrNotFound {
+			return err
+		}
+		return ctx.RedirectToGet()
+	default:
+		return ctx.RedirectToGet()
+	}
+
+	err = course.SetOption(ctx, id, opt)
+	if err != nil {
+		return err
+	}
+
+	return ctx.RedirectToGet()
+}
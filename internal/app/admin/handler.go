@@ -16,8 +16,17 @@ func Mount(m *httpmux.Mux) {
 	mux.Handle("/users", methodmux.Get(
 		hime.Handler(getUsers),
 	))
-	mux.Handle("/courses", methodmux.Get(
+	mux.Handle("/courses", methodmux.GetPost(
 		hime.Handler(getCourses),
+		hime.Handler(postCourses),
+	))
+	mux.Handle("/categories", methodmux.GetPost(
+		hime.Handler(getCategories),
+		hime.Handler(postCategories),
+	))
+	mux.Handle("/reviews", methodmux.GetPost(
+		hime.Handler(getReviews),
+		hime.Handler(postReviews),
 	))
 	mux.Handle("/payments/pending", methodmux.GetPost(
 		hime.Handler(getPendingPayments),
@@ -116,7 +116,7 @@ func getPendingPayments(ctx *hime.Context) error {
 	p := view.Page(ctx)
 	p.Data["Navbar"] = "admin.payment.pending"
 	p.Data["Payments"] = list
-	p.Data["Paginate"] = pn
+	p.Data["Paginate"] = view.Paginate(ctx, pn)
 	return ctx.View("admin.payments", p)
 }
 
@@ -137,6 +137,6 @@ func getHistoryPayments(ctx *hime.Context) error {
 	p := view.Page(ctx)
 	p.Data["Navbar"] = "admin.payment.history"
 	p.Data["Payments"] = list
-	p.Data["Paginate"] = pn
+	p.Data["Paginate"] = view.Paginate(ctx, pn)
 	return ctx.View("admin.payments", p)
 }
@@ -0,0 +1,52 @@
+package admin
+
+import (
+	"strconv"
+
+	"github.com/acoshift/paginate"
+	"github.com/moonrhythm/hime"
+
+	"github.com/acoshift/acourse/internal/app/view"
+	"github.com/acoshift/acourse/internal/pkg/admin"
+	"github.com/acoshift/acourse/internal/pkg/review"
+)
+
+func getReviews(ctx *hime.Context) error {
+	cnt, err := admin.CountReviews(ctx)
+	if err != nil {
+		return err
+	}
+
+	pg, _ := strconv.ParseInt(ctx.FormValue("page"), 10, 64)
+	pn := paginate.New(pg, 30, cnt)
+
+	list, err := admin.GetReviews(ctx, pn.Limit(), pn.Offset())
+	if err != nil {
+		return err
+	}
+
+	p := view.Page(ctx)
+	p.Data["Navbar"] = "admin.reviews"
+	p.Data["Reviews"] = list
+	p.Data["Paginate"] = view.Paginate(ctx, pn)
+	return ctx.View("admin.reviews", p)
+}
+
+func postReviews(ctx *hime.Context) error {
+	courseID := ctx.PostFormValue("courseId")
+	userID := ctx.PostFormValue("userId")
+	value := ctx.PostFormValue("value") == "1"
+
+	var err error
+	switch ctx.PostFormValue("action") {
+	case "hide":
+		err = review.SetHidden(ctx, courseID, userID, value)
+	case "flag":
+		err = review.SetFlagged(ctx, courseID, userID, value)
+	}
+	if err != nil {
+		return err
+	}
+
+	return ctx.RedirectToGet()
+}
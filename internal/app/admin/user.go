@@ -27,6 +27,6 @@ func getUsers(ctx *hime.Context) error {
 	p := view.Page(ctx)
 	p.Data["Navbar"] = "admin.users"
 	p.Data["Users"] = list
-	p.Data["Paginate"] = pn
+	p.Data["Paginate"] = view.Paginate(ctx, pn)
 	return ctx.View("admin.users", p)
 }
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/acoshift/methodmux"
+	"github.com/acoshift/prefixhandler"
+	"github.com/moonrhythm/hime"
+
+	"github.com/acoshift/acourse/internal/app/view"
+	"github.com/acoshift/acourse/internal/pkg/certificate"
+)
+
+type (
+	certificateCodeKey struct{}
+	certificateKey     struct{}
+)
+
+func newCertificateHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/", methodmux.Get(
+		hime.Handler(getCertificate),
+	))
+	mux.Handle("/pdf", methodmux.Get(
+		hime.Handler(getCertificatePDF),
+	))
+
+	return hime.Handler(func(ctx *hime.Context) error {
+		code := prefixhandler.Get(ctx, certificateCodeKey{})
+
+		x, err := certificate.Get(ctx, code)
+		if err == certificate.ErrNotFound {
+			return view.NotFound(ctx)
+		}
+		if err != nil {
+			return err
+		}
+
+		ctx = ctx.WithValue(certificateKey{}, x)
+
+		return ctx.Handle(mux)
+	})
+}
+
+func getCertificate(ctx *hime.Context) error {
+	if ctx.URL.Path != "/" {
+		return view.NotFound(ctx)
+	}
+
+	x := ctx.Value(certificateKey{}).(*certificate.Certificate)
+
+	p := view.Page(ctx)
+	p.Meta.Title = x.Course.Title
+	p.Meta.URL = ctx.Global("baseURL").(string) + ctx.Route("app.certificate", x.Code)
+	p.Data["Certificate"] = x
+	p.Data["CanRenderPDF"] = certificate.CanRender()
+	return ctx.View("app.certificate", p)
+}
+
+func getCertificatePDF(ctx *hime.Context) error {
+	x := ctx.Value(certificateKey{}).(*certificate.Certificate)
+
+	var buf bytes.Buffer
+	err := certificate.Render(&buf, x, ctx.Global("baseURL").(string)+ctx.Route("app.certificate", x.Code))
+	if err == certificate.ErrNoFont {
+		return ctx.Status(http.StatusServiceUnavailable).StatusText()
+	}
+	if err != nil {
+		return err
+	}
+
+	ctx.SetHeader("Content-Type", "application/pdf")
+	ctx.SetHeader("Content-Disposition", `inline; filename="certificate-`+x.Code+`.pdf"`)
+	return ctx.Bytes(buf.Bytes())
+}
@@ -2,20 +2,29 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/acoshift/methodmux"
+	"github.com/acoshift/paginate"
 	"github.com/acoshift/prefixhandler"
+	"github.com/dustin/go-humanize"
 	"github.com/moonrhythm/hime"
 	"github.com/satori/go.uuid"
 
 	"github.com/acoshift/acourse/internal/app/view"
+	"github.com/acoshift/acourse/internal/pkg/certificate"
 	"github.com/acoshift/acourse/internal/pkg/context/appctx"
 	"github.com/acoshift/acourse/internal/pkg/course"
+	"github.com/acoshift/acourse/internal/pkg/discussion"
 	"github.com/acoshift/acourse/internal/pkg/me"
 	"github.com/acoshift/acourse/internal/pkg/payment"
+	"github.com/acoshift/acourse/internal/pkg/progress"
+	"github.com/acoshift/acourse/internal/pkg/quiz"
+	"github.com/acoshift/acourse/internal/pkg/review"
 )
 
 type (
@@ -30,25 +39,39 @@ func newCourseHandler() http.Handler {
 	mux.Handle("/", methodmux.Get(
 		hime.Handler(c.view),
 	))
-	mux.Handle("/content", mustSignedIn(methodmux.Get(
+	mux.Handle("/content", mustSignedIn(methodmux.GetPost(
 		hime.Handler(c.content),
+		hime.Handler(c.postContent),
 	)))
 	mux.Handle("/enroll", mustSignedIn(methodmux.GetPost(
 		hime.Handler(c.enroll),
 		hime.Handler(c.postEnroll),
 	)))
-	mux.Handle("/assignment", mustSignedIn(methodmux.Get(
+	mux.Handle("/assignment", mustSignedIn(methodmux.GetPost(
 		hime.Handler(c.assignment),
+		hime.Handler(c.postAssignment),
+	)))
+	mux.Handle("/review", mustSignedIn(methodmux.Post(
+		hime.Handler(c.postReview),
+	)))
+	mux.Handle("/certificate", mustSignedIn(methodmux.Get(
+		hime.Handler(c.certificate),
 	)))
 
 	return hime.Handler(func(ctx *hime.Context) error {
 		link := prefixhandler.Get(ctx, courseIDKey{})
 
 		courseID := link
+		oldURL := false
 		_, err := uuid.FromString(link)
 		if err != nil {
 			// link can not parse to uuid get course id from url
 			courseID, err = course.GetIDByURL(ctx, link)
+			if err == course.ErrNotFound {
+				// link may be course's old url, redirect to new link below
+				courseID, err = course.GetIDByOldURL(ctx, link)
+				oldURL = err == nil
+			}
 			if err == course.ErrNotFound {
 				return view.NotFound(ctx)
 			}
@@ -67,6 +90,10 @@ func newCourseHandler() http.Handler {
 
 		// if course has url, redirect to course url
 		if l := x.Link(); l != link {
+			// old url moved permanently to the current link
+			if oldURL && ctx.Request.Method == http.MethodGet {
+				ctx.Status(http.StatusMovedPermanently)
+			}
 			return ctx.RedirectTo("app.course", l, ctx.URL.Path)
 		}
 
@@ -107,9 +134,38 @@ func (ctrl *courseCtrl) view(ctx *hime.Context) error {
 		}
 	}
 
-	var owned bool
+	var role course.Role
 	if u != nil {
-		owned = u.ID == c.Owner.ID
+		role, err = course.GetRole(ctx, c.ID, u.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	prerequisites, err := course.GetPrerequisites(ctx, c.ID, appctx.GetUserID(ctx))
+	if err != nil {
+		return err
+	}
+
+	var myReview *review.Review
+	if enrolled {
+		myReview, err = review.Get(ctx, c.ID, u.ID)
+		if err != nil && err != review.ErrNotFound {
+			return err
+		}
+	}
+
+	cnt, err := review.Count(ctx, c.ID)
+	if err != nil {
+		return err
+	}
+
+	pg, _ := strconv.ParseInt(ctx.FormValue("page"), 10, 64)
+	pn := paginate.New(pg, 10, cnt)
+
+	reviews, err := review.List(ctx, c.ID, pn.Limit(), pn.Offset())
+	if err != nil {
+		return err
 	}
 
 	p := view.Page(ctx)
@@ -119,8 +175,13 @@ func (ctrl *courseCtrl) view(ctx *hime.Context) error {
 	p.Meta.URL = ctx.Global("baseURL").(string) + ctx.Route("app.course", url.PathEscape(c.Link()))
 	p.Data["Course"] = c
 	p.Data["Enrolled"] = enrolled
-	p.Data["Owned"] = owned
+	p.Data["Owned"] = role.IsMember()
+	p.Data["Role"] = role
 	p.Data["PendingEnroll"] = pendingEnroll
+	p.Data["Prerequisites"] = prerequisites
+	p.Data["MyReview"] = myReview
+	p.Data["Reviews"] = reviews
+	p.Data["Paginate"] = view.Paginate(ctx, pn)
 	return ctx.View("app.course", p)
 }
 
@@ -133,17 +194,57 @@ func (ctrl *courseCtrl) content(ctx *hime.Context) error {
 		return err
 	}
 
-	if !enrolled && u.ID != x.Owner.ID {
-		return ctx.Status(http.StatusForbidden).StatusText()
+	if !enrolled {
+		// course's members can view course without enroll
+		role, err := course.GetRole(ctx, x.ID, u.ID)
+		if err != nil {
+			return err
+		}
+		if !role.IsMember() {
+			return ctx.Status(http.StatusForbidden).StatusText()
+		}
 	}
 
-	contents, err := course.GetContents(ctx, x.ID)
+	sections, err := course.GetSections(ctx, x.ID)
 	if err != nil {
 		return err
 	}
 
+	var contents []*course.Content
+	contentIndex := make(map[string]int)
+	for _, s := range sections {
+		for _, c := range s.Contents {
+			contentIndex[c.ID] = len(contents)
+			contents = append(contents, c)
+		}
+	}
+
+	// contents which are not released for enrolled user, course's members can view all contents
+	locked := make(map[string]time.Time)
+	if enrolled {
+		enrolledAt, err := course.GetEnrolledAt(ctx, u.ID, x.ID)
+		if err != nil {
+			return err
+		}
+		for _, c := range contents {
+			if !c.Released(enrolledAt, x.Start) {
+				locked[c.ID] = c.ReleaseAt(enrolledAt, x.Start)
+			}
+		}
+	}
+
 	var content *course.Content
 	pg, _ := strconv.Atoi(ctx.FormValue("p"))
+	if ctx.FormValue("p") == "" {
+		// resume where user left off
+		lastID, err := progress.GetLastViewed(ctx, u.ID, x.ID)
+		if err != nil && err != progress.ErrNotFound {
+			return err
+		}
+		if i, ok := contentIndex[lastID]; ok {
+			pg = i
+		}
+	}
 	if pg < 0 {
 		pg = 0
 	}
@@ -154,22 +255,206 @@ func (ctrl *courseCtrl) content(ctx *hime.Context) error {
 		content = contents[pg]
 	}
 
+	completed, err := progress.GetCompleted(ctx, u.ID, x.ID)
+	if err != nil {
+		return err
+	}
+
+	var (
+		attachments []*course.Attachment
+		qz          *quiz.Quiz
+		attempts    []*quiz.Attempt
+		comments    []*discussion.Comment
+	)
+	if content != nil && locked[content.ID].IsZero() {
+		err = progress.View(ctx, u.ID, x.ID, content.ID)
+		if err != nil {
+			return err
+		}
+
+		attachments, err = course.GetAttachments(ctx, content.ID)
+		if err != nil {
+			return err
+		}
+
+		qz, err = quiz.Get(ctx, content.ID)
+		if err != nil && err != quiz.ErrNotFound {
+			return err
+		}
+		if qz != nil {
+			attempts, err = quiz.GetAttempts(ctx, u.ID, qz.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		comments, err = discussion.List(ctx, content.ID)
+		if err != nil {
+			return err
+		}
+	}
+
 	p := view.Page(ctx)
 	p.Meta.Title = x.Title
 	p.Meta.Desc = x.ShortDesc
 	p.Meta.Image = x.Image
 	p.Data["Course"] = x
+	p.Data["Sections"] = sections
 	p.Data["Contents"] = contents
+	p.Data["ContentIndex"] = contentIndex
 	p.Data["Content"] = content
+	p.Data["Attachments"] = attachments
+	p.Data["Quiz"] = qz
+	p.Data["QuizAttempts"] = attempts
+	p.Data["Comments"] = comments
+	p.Data["ContentPage"] = pg
+	p.Data["Completed"] = completed
+	p.Data["Locked"] = locked
 	return ctx.View("app.course-content", p)
 }
 
+func (ctrl *courseCtrl) postContent(ctx *hime.Context) error {
+	u := appctx.GetUser(ctx)
+	x := ctrl.getCourse(ctx)
+
+	enrolled, err := course.IsEnroll(ctx, u.ID, x.ID)
+	if err != nil {
+		return err
+	}
+
+	if !enrolled {
+		// course's members can view course without enroll
+		role, err := course.GetRole(ctx, x.ID, u.ID)
+		if err != nil {
+			return err
+		}
+		if !role.IsMember() {
+			return ctx.Status(http.StatusForbidden).StatusText()
+		}
+	}
+
+	contentID := ctx.PostFormValue("contentId")
+	pg, _ := strconv.Atoi(ctx.PostFormValue("p"))
+
+	courseID, err := course.GetIDFromContent(ctx, contentID)
+	if err != nil && err != course.ErrNotFound {
+		return err
+	}
+	if err == course.ErrNotFound || courseID != x.ID {
+		return ctx.RedirectTo("app.course", x.Link(), "content")
+	}
+
+	// content which is not released can not be completed, answered or discussed
+	if enrolled {
+		content, err := course.GetContent(ctx, contentID)
+		if err != nil {
+			return err
+		}
+
+		enrolledAt, err := course.GetEnrolledAt(ctx, u.ID, x.ID)
+		if err != nil {
+			return err
+		}
+
+		if !content.Released(enrolledAt, x.Start) {
+			return ctx.RedirectTo("app.course", x.Link(), "content", ctx.Param("p", pg))
+		}
+	}
+
+	switch ctx.PostFormValue("action") {
+	case "complete":
+		err = progress.SetCompleted(ctx, u.ID, x.ID, contentID, true)
+		if err != nil {
+			return err
+		}
+
+		// go to next content
+		return ctx.RedirectTo("app.course", x.Link(), "content", ctx.Param("p", pg+1))
+	case "uncomplete":
+		err = progress.SetCompleted(ctx, u.ID, x.ID, contentID, false)
+		if err != nil {
+			return err
+		}
+	case "quiz":
+		if x.Archived() {
+			appctx.GetFlash(ctx).Add("Errors", "คอร์สนี้ถูกเก็บถาวรแล้ว ไม่สามารถทำแบบทดสอบได้")
+			break
+		}
+
+		qz, err := quiz.Get(ctx, contentID)
+		if err == quiz.ErrNotFound {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		answers := make(map[string][]string)
+		for _, q := range qz.Questions {
+			answers[q.ID] = ctx.PostForm["q_"+q.ID]
+		}
+
+		_, err = quiz.Submit(ctx, u.ID, qz, answers)
+		if err == quiz.ErrNoAttemptsLeft {
+			appctx.GetFlash(ctx).Add("Errors", "ทำแบบทดสอบครบจำนวนครั้งแล้ว")
+			break
+		}
+		if err != nil {
+			return err
+		}
+	case "comment":
+		if x.Archived() {
+			appctx.GetFlash(ctx).Add("Errors", "คอร์สนี้ถูกเก็บถาวรแล้ว ไม่สามารถแสดงความคิดเห็นได้")
+			break
+		}
+
+		_, err = discussion.Create(ctx, &discussion.CreateArgs{
+			ContentID: contentID,
+			UserID:    u.ID,
+			ParentID:  ctx.PostFormValue("parentId"),
+			Body:      ctx.PostFormValueTrimSpace("body"),
+		})
+		if err == discussion.ErrInvalidBody {
+			appctx.GetFlash(ctx).Add("Errors", "กรุณาใส่ข้อความ")
+			break
+		}
+		if err != nil && err != discussion.ErrNotFound {
+			return err
+		}
+	case "updateComment":
+		if x.Archived() {
+			appctx.GetFlash(ctx).Add("Errors", "คอร์สนี้ถูกเก็บถาวรแล้ว ไม่สามารถแก้ไขความคิดเห็นได้")
+			break
+		}
+
+		err = discussion.Update(ctx, ctx.PostFormValue("commentId"), u.ID, ctx.PostFormValueTrimSpace("body"))
+		if err == discussion.ErrInvalidBody {
+			appctx.GetFlash(ctx).Add("Errors", "กรุณาใส่ข้อความ")
+			break
+		}
+		if err != nil && err != discussion.ErrNotFound {
+			return err
+		}
+	case "deleteComment":
+		err = discussion.Delete(ctx, ctx.PostFormValue("commentId"), u.ID)
+		if err != nil && err != discussion.ErrNotFound {
+			return err
+		}
+	}
+
+	return ctx.RedirectTo("app.course", x.Link(), "content", ctx.Param("p", pg))
+}
+
 func (ctrl *courseCtrl) enroll(ctx *hime.Context) error {
 	u := appctx.GetUser(ctx)
 	c := ctrl.getCourse(ctx)
 
-	// owner redirect to c content
-	if u != nil && u.ID == c.Owner.ID {
+	// course's members redirect to c content
+	role, err := course.GetRole(ctx, c.ID, u.ID)
+	if err != nil {
+		return err
+	}
+	if role.IsMember() {
 		return ctx.RedirectTo("app.course", c.Link(), "content")
 	}
 
@@ -191,12 +476,38 @@ func (ctrl *courseCtrl) enroll(ctx *hime.Context) error {
 		return ctx.RedirectTo("app.course", c.Link())
 	}
 
+	// archived course closed for enroll
+	if c.Archived() {
+		return ctx.RedirectTo("app.course", c.Link())
+	}
+
+	prerequisites, err := course.GetPrerequisites(ctx, c.ID, u.ID)
+	if err != nil {
+		return err
+	}
+
+	// required prerequisites block enroll, others only warn
+	blocked, warned := false, false
+	for _, x := range prerequisites {
+		if x.Completed {
+			continue
+		}
+		if x.Required {
+			blocked = true
+		} else {
+			warned = true
+		}
+	}
+
 	p := view.Page(ctx)
 	p.Meta.Title = c.Title
 	p.Meta.Desc = c.ShortDesc
 	p.Meta.Image = c.Image
 	p.Meta.URL = ctx.Global("baseURL").(string) + ctx.Route("app.course", url.PathEscape(c.Link()))
 	p.Data["Course"] = c
+	p.Data["Prerequisites"] = prerequisites
+	p.Data["PrerequisiteBlocked"] = blocked
+	p.Data["PrerequisiteWarned"] = warned
 	return ctx.View("app.course-enroll", p)
 }
 
@@ -204,8 +515,12 @@ func (ctrl *courseCtrl) postEnroll(ctx *hime.Context) error {
 	u := appctx.GetUser(ctx)
 	x := ctrl.getCourse(ctx)
 
-	// owner redirect to course content
-	if u != nil && u.ID == x.Owner.ID {
+	// course's members redirect to course content
+	role, err := course.GetRole(ctx, x.ID, u.ID)
+	if err != nil {
+		return err
+	}
+	if role.IsMember() {
 		return ctx.RedirectTo("app.course", x.Link(), "content")
 	}
 
@@ -227,6 +542,11 @@ func (ctrl *courseCtrl) postEnroll(ctx *hime.Context) error {
 		return ctx.RedirectTo("app.course", x.Link())
 	}
 
+	// archived course closed for enroll
+	if x.Archived() {
+		return ctx.RedirectTo("app.course", x.Link())
+	}
+
 	f := appctx.GetFlash(ctx)
 
 	price, _ := strconv.ParseFloat(ctx.FormValue("price"), 64)
@@ -242,6 +562,10 @@ func (ctrl *courseCtrl) postEnroll(ctx *hime.Context) error {
 		f.Add("Errors", "กรุณาอัพโหลดรูปภาพ")
 		return ctx.RedirectToGet()
 	}
+	if err == me.ErrPrerequisiteRequired {
+		f.Add("Errors", "กรุณาเรียนคอร์สที่ต้องเรียนก่อนให้จบก่อนสมัครเรียน")
+		return ctx.RedirectToGet()
+	}
 	if err != nil {
 		f.Add("Errors", "image required")
 		return ctx.RedirectToGet()
@@ -259,8 +583,15 @@ func (ctrl *courseCtrl) assignment(ctx *hime.Context) error {
 		return err
 	}
 
-	if !enrolled && u.ID != c.Owner.ID {
-		return ctx.Status(http.StatusForbidden).StatusText()
+	if !enrolled {
+		// course's members can view course without enroll
+		role, err := course.GetRole(ctx, c.ID, u.ID)
+		if err != nil {
+			return err
+		}
+		if !role.IsMember() {
+			return ctx.Status(http.StatusForbidden).StatusText()
+		}
 	}
 
 	assignments, err := course.GetAssignments(ctx, c.ID)
@@ -268,6 +599,17 @@ func (ctrl *courseCtrl) assignment(ctx *hime.Context) error {
 		return err
 	}
 
+	submissions, err := course.GetUserSubmissions(ctx, u.ID, c.ID)
+	if err != nil {
+		return err
+	}
+
+	// owner did not enroll, deadlines which relative to enroll date will not show
+	enrolledAt, err := course.GetEnrolledAt(ctx, u.ID, c.ID)
+	if err != nil && err != course.ErrNotFound {
+		return err
+	}
+
 	p := view.Page(ctx)
 	p.Meta.Title = c.Title
 	p.Meta.Desc = c.ShortDesc
@@ -275,5 +617,80 @@ func (ctrl *courseCtrl) assignment(ctx *hime.Context) error {
 	p.Meta.URL = ctx.Global("baseURL").(string) + ctx.Route("app.course", url.PathEscape(c.Link()))
 	p.Data["Course"] = c
 	p.Data["Assignments"] = assignments
+	p.Data["Submissions"] = submissions
+	p.Data["Enrolled"] = enrolled
+	p.Data["EnrolledAt"] = enrolledAt
 	return ctx.View("app.course-assignment", p)
 }
+
+func (ctrl *courseCtrl) postAssignment(ctx *hime.Context) error {
+	u := appctx.GetUser(ctx)
+	c := ctrl.getCourse(ctx)
+
+	// only enrolled user can submit assignment
+	enrolled, err := course.IsEnroll(ctx, u.ID, c.ID)
+	if err != nil {
+		return err
+	}
+	if !enrolled {
+		return ctx.Status(http.StatusForbidden).StatusText()
+	}
+
+	f := appctx.GetFlash(ctx)
+
+	if c.Archived() {
+		f.Add("Errors", "คอร์สนี้ถูกเก็บถาวรแล้ว ไม่สามารถส่งการบ้านได้")
+		return ctx.RedirectToGet()
+	}
+
+	fh, _ := ctx.FormFileHeaderNotEmpty("file")
+
+	_, err = course.SubmitAssignment(ctx, &course.SubmitAssignmentArgs{
+		UserID:       u.ID,
+		CourseID:     c.ID,
+		AssignmentID: ctx.PostFormValue("assignmentId"),
+		File:         fh,
+		Answer:       ctx.PostFormValue("answer"),
+	})
+	if err == course.ErrInvalidAttachment {
+		f.Add("Errors", "กรุณาเลือกไฟล์")
+		return ctx.RedirectToGet()
+	}
+	if err == course.ErrAttachmentTooLarge {
+		f.Add("Errors", fmt.Sprintf("ไฟล์ต้องมีขนาดไม่เกิน %s", humanize.Bytes(course.MaxAttachmentSize)))
+		return ctx.RedirectToGet()
+	}
+	if err == course.ErrAssignmentClosed {
+		f.Add("Errors", "การบ้านนี้ปิดรับแล้ว")
+		return ctx.RedirectToGet()
+	}
+	if err != nil && err != course.ErrNotFound {
+		return err
+	}
+
+	return ctx.RedirectToGet()
+}
+
+func (ctrl *courseCtrl) certificate(ctx *hime.Context) error {
+	u := appctx.GetUser(ctx)
+	c := ctrl.getCourse(ctx)
+
+	enrolled, err := course.IsEnroll(ctx, u.ID, c.ID)
+	if err != nil {
+		return err
+	}
+	if !enrolled {
+		return ctx.Status(http.StatusForbidden).StatusText()
+	}
+
+	code, err := certificate.Issue(ctx, u.ID, c.ID)
+	if err == certificate.ErrNotCompleted {
+		appctx.GetFlash(ctx).Add("Errors", fmt.Sprintf("กรุณาเรียนและส่งการบ้านคอร์ส %s ให้ครบก่อนรับใบประกาศนียบัตร", c.Title))
+		return ctx.RedirectTo("app.profile")
+	}
+	if err != nil {
+		return err
+	}
+
+	return ctx.RedirectTo("app.certificate", code, "pdf")
+}
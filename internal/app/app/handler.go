@@ -12,6 +12,9 @@ func Mount(m *httpmux.Mux) {
 	m.Handle("/", methodmux.Get(
 		hime.Handler(index),
 	))
+	m.Handle("/search", methodmux.Get(
+		hime.Handler(getSearch),
+	))
 	m.Handle("/signout", methodmux.Post(
 		hime.Handler(signOut),
 	))
@@ -27,4 +30,7 @@ func Mount(m *httpmux.Mux) {
 
 	// course
 	m.Handle("/course/", prefixhandler.New("/course", courseIDKey{}, newCourseHandler()))
+
+	// certificate
+	m.Handle("/certificate/", prefixhandler.New("/certificate", certificateCodeKey{}, newCertificateHandler()))
 }
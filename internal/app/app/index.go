@@ -1,6 +1,9 @@
 package app
 
 import (
+	"strconv"
+
+	"github.com/acoshift/paginate"
 	"github.com/moonrhythm/hime"
 
 	"github.com/acoshift/acourse/internal/app/view"
@@ -12,12 +15,50 @@ func index(ctx *hime.Context) error {
 		return view.NotFound(ctx)
 	}
 
-	courses, err := course.GetPublicCards(ctx)
+	f := course.PublicFilter{
+		Category: ctx.FormValue("category"),
+		Tag:      ctx.FormValue("tag"),
+	}
+	f.Type, _ = strconv.Atoi(ctx.FormValue("type"))
+	switch ctx.FormValue("price") {
+	case "free":
+		f.Price = course.Free
+	case "paid":
+		f.Price = course.Paid
+	}
+	switch ctx.FormValue("sort") {
+	case "newest":
+		f.Sort = course.SortNewest
+	case "start":
+		f.Sort = course.SortStart
+	case "popular":
+		f.Sort = course.SortPopular
+	case "price":
+		f.Sort = course.SortPrice
+	}
+
+	cnt, err := course.CountPublicCards(ctx, &f)
+	if err != nil {
+		return err
+	}
+
+	pg, _ := strconv.ParseInt(ctx.FormValue("page"), 10, 64)
+	pn := paginate.New(pg, 24, cnt)
+
+	courses, err := course.GetPublicCards(ctx, &f, pn.Limit(), pn.Offset())
+	if err != nil {
+		return err
+	}
+
+	categories, err := course.GetCategories(ctx)
 	if err != nil {
 		return err
 	}
 
 	p := view.Page(ctx)
 	p.Data["Courses"] = courses
+	p.Data["Categories"] = categories
+	p.Data["Filter"] = ctx.URL.Query()
+	p.Data["Paginate"] = view.Paginate(ctx, pn)
 	return ctx.View("app.index", p)
 }
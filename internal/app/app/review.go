@@ -0,0 +1,66 @@
+package app
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/moonrhythm/hime"
+
+	"github.com/acoshift/acourse/internal/pkg/context/appctx"
+	"github.com/acoshift/acourse/internal/pkg/course"
+	"github.com/acoshift/acourse/internal/pkg/review"
+)
+
+func (ctrl *courseCtrl) postReview(ctx *hime.Context) error {
+	u := appctx.GetUser(ctx)
+	c := ctrl.getCourse(ctx)
+	f := appctx.GetFlash(ctx)
+
+	switch ctx.PostFormValue("action") {
+	case "save":
+		if c.Archived() {
+			f.Add("Errors", "คอร์สนี้ถูกเก็บถาวรแล้ว ไม่สามารถรีวิวได้")
+			break
+		}
+
+		rating, _ := strconv.Atoi(ctx.PostFormValue("rating"))
+		err := review.Save(ctx, &review.SaveArgs{
+			CourseID: c.ID,
+			UserID:   u.ID,
+			Rating:   rating,
+			Review:   ctx.PostFormValueTrimSpace("review"),
+		})
+		if err == review.ErrNotEnrolled {
+			return ctx.Status(http.StatusForbidden).StatusText()
+		}
+		if err == review.ErrInvalidRating {
+			f.Add("Errors", "กรุณาให้คะแนน 1 - 5 ดาว")
+			return ctx.RedirectTo("app.course", c.Link())
+		}
+		if err != nil {
+			return err
+		}
+	case "reply":
+		// only course's instructors can reply
+		ok, err := course.Can(ctx, c.ID, u.ID, course.EditContent)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ctx.Status(http.StatusForbidden).StatusText()
+		}
+
+		err = review.Reply(ctx, c.ID, ctx.PostFormValue("userId"), ctx.PostFormValueTrimSpace("reply"))
+		if err != nil && err != review.ErrNotFound {
+			return err
+		}
+	case "flag":
+		err := review.SetFlagged(ctx, c.ID, ctx.PostFormValue("userId"), true)
+		if err != nil {
+			return err
+		}
+		f.Set("Flagged", "1")
+	}
+
+	return ctx.RedirectTo("app.course", c.Link())
+}
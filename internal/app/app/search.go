@@ -0,0 +1,36 @@
+package app
+
+import (
+	"strconv"
+
+	"github.com/acoshift/paginate"
+	"github.com/moonrhythm/hime"
+
+	"github.com/acoshift/acourse/internal/app/view"
+	"github.com/acoshift/acourse/internal/pkg/search"
+)
+
+func getSearch(ctx *hime.Context) error {
+	q := ctx.FormValue("q")
+
+	cnt, err := search.CountCourses(ctx, q)
+	if err != nil {
+		return err
+	}
+
+	pg, _ := strconv.ParseInt(ctx.FormValue("page"), 10, 64)
+	pn := paginate.New(pg, 20, cnt)
+
+	list, err := search.Courses(ctx, q, pn.Limit(), pn.Offset())
+	if err != nil {
+		return err
+	}
+
+	p := view.Page(ctx)
+	p.Meta.Title = "ค้นหา " + q
+	p.Data["Query"] = q
+	p.Data["Courses"] = list
+	p.Data["Count"] = cnt
+	p.Data["Paginate"] = view.Paginate(ctx, pn)
+	return ctx.View("app.search", p)
+}
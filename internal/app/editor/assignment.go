@@ -0,0 +1,200 @@
+package editor
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/moonrhythm/hime"
+
+	"github.com/acoshift/acourse/internal/app/view"
+	"github.com/acoshift/acourse/internal/pkg/config"
+	"github.com/acoshift/acourse/internal/pkg/context/appctx"
+	"github.com/acoshift/acourse/internal/pkg/course"
+)
+
+func getAssignmentList(ctx *hime.Context) error {
+	id := ctx.FormValue("id")
+
+	c, err := course.Get(ctx, id)
+	if err == course.ErrNotFound {
+		return view.NotFound(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	assignments, err := course.GetAssignments(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	p := view.Page(ctx)
+	p.Data["Course"] = c
+	p.Data["Assignments"] = assignments
+	p.Data["Role"] = getRole(ctx)
+	return ctx.View("editor.assignment", p)
+}
+
+func postAssignmentList(ctx *hime.Context) error {
+	// assistant can view assignments but can not edit
+	if !getRole(ctx).Can(course.EditContent) {
+		return ctx.Status(http.StatusForbidden).StatusText()
+	}
+
+	id := ctx.FormValue("id")
+	assignmentID := ctx.FormValue("assignmentId")
+
+	f := appctx.GetFlash(ctx)
+
+	var (
+		title    = ctx.PostFormValueTrimSpace("title")
+		desc     = ctx.PostFormValue("desc")
+		required = ctx.PostFormValue("required") != ""
+		dueAt    time.Time
+		dueDays  int
+		lateDays int
+	)
+	if v := ctx.PostFormValue("dueAt"); v != "" {
+		dueAt, _ = time.ParseInLocation("2006-01-02T15:04", v, config.Location())
+	}
+	dueDays, _ = strconv.Atoi(ctx.PostFormValue("dueDays"))
+	lateDays, _ = strconv.Atoi(ctx.PostFormValue("lateDays"))
+	if dueDays < 0 || lateDays < 0 {
+		f.Add("Errors", "จำนวนวันติดลบไม่ได้")
+		return ctx.RedirectToGet()
+	}
+
+	switch ctx.FormValue("action") {
+	case "create":
+		_, err := course.CreateAssignment(ctx, &course.CreateAssignmentArgs{
+			CourseID: id,
+			Title:    title,
+			Desc:     desc,
+			Required: required,
+			DueAt:    dueAt,
+			DueDays:  dueDays,
+			LateDays: lateDays,
+		})
+		if err != nil {
+			f.Add("Errors", err.Error())
+			return ctx.RedirectToGet()
+		}
+	case "update":
+		err := course.UpdateAssignment(ctx, &course.UpdateAssignmentArgs{
+			CourseID:     id,
+			AssignmentID: assignmentID,
+			Title:        title,
+			Desc:         desc,
+			Required:     required,
+			DueAt:        dueAt,
+			DueDays:      dueDays,
+			LateDays:     lateDays,
+		})
+		if err == course.ErrNotFound {
+			return ctx.RedirectToGet()
+		}
+		if err != nil {
+			f.Add("Errors", err.Error())
+			return ctx.RedirectToGet()
+		}
+	case "open", "close":
+		err := course.SetAssignmentOpen(ctx, id, assignmentID, ctx.FormValue("action") == "open")
+		if err == course.ErrNotFound {
+			return ctx.RedirectToGet()
+		}
+		if err != nil {
+			return err
+		}
+	case "delete":
+		err := course.DeleteAssignment(ctx, id, assignmentID)
+		if err == course.ErrAssignmentHasSubmissions {
+			f.Add("Errors", "ไม่สามารถลบการบ้านที่มีผู้ส่งแล้วได้")
+			return ctx.RedirectToGet()
+		}
+		if err != nil {
+			return err
+		}
+	case "moveUp", "moveDown":
+		assignments, err := course.GetAssignments(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		from := -1
+		ids := make([]string, len(assignments))
+		for i, x := range assignments {
+			if x.ID == assignmentID {
+				from = i
+			}
+			ids[i] = x.ID
+		}
+		if from < 0 {
+			return ctx.RedirectToGet()
+		}
+
+		to := from - 1
+		if ctx.FormValue("action") == "moveDown" {
+			to = from + 1
+		}
+
+		err = course.ReorderAssignments(ctx, id, moveID(ids, from, to))
+		if err == course.ErrInvalidAssignments {
+			return ctx.RedirectToGet()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return ctx.RedirectToGet()
+}
+
+func getAssignmentSubmission(ctx *hime.Context) error {
+	id := ctx.FormValue("id")
+
+	c, err := course.Get(ctx, id)
+	if err == course.ErrNotFound {
+		return view.NotFound(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	assignment, err := course.GetAssignment(ctx, id, ctx.FormValue("assignmentId"))
+	if err == course.ErrNotFound {
+		return view.NotFound(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	submissions, err := course.GetSubmissions(ctx, assignment.ID)
+	if err != nil {
+		return err
+	}
+
+	p := view.Page(ctx)
+	p.Data["Course"] = c
+	p.Data["Assignment"] = assignment
+	p.Data["Submissions"] = submissions
+	return ctx.View("editor.assignment-submission", p)
+}
+
+func postAssignmentSubmission(ctx *hime.Context) error {
+	id := ctx.FormValue("id")
+
+	score, _ := strconv.Atoi(ctx.PostFormValue("score"))
+
+	err := course.GradeSubmission(ctx, &course.GradeSubmissionArgs{
+		CourseID:     id,
+		SubmissionID: ctx.PostFormValue("submissionId"),
+		Score:        score,
+		Passed:       ctx.PostFormValue("passed") == "1",
+		Feedback:     ctx.PostFormValue("feedback"),
+	})
+	if err != nil && err != course.ErrNotFound {
+		return err
+	}
+
+	return ctx.RedirectToGet()
+}
@@ -1,13 +1,17 @@
 package editor
 
 import (
-	"net/http"
+	"fmt"
+	"strconv"
 
+	"github.com/dustin/go-humanize"
 	"github.com/moonrhythm/hime"
 
 	"github.com/acoshift/acourse/internal/app/view"
 	"github.com/acoshift/acourse/internal/pkg/context/appctx"
 	"github.com/acoshift/acourse/internal/pkg/course"
+	"github.com/acoshift/acourse/internal/pkg/quiz"
+	"github.com/acoshift/acourse/internal/pkg/video"
 )
 
 func getContentList(ctx *hime.Context) error {
@@ -21,22 +25,79 @@ func getContentList(ctx *hime.Context) error {
 		return err
 	}
 
-	contents, err := course.GetContents(ctx, id)
+	sections, err := course.GetSections(ctx, id)
 	if err != nil {
 		return err
 	}
 
 	p := view.Page(ctx)
 	p.Data["Course"] = c
-	p.Data["Contents"] = contents
+	p.Data["Sections"] = sections
 	return ctx.View("editor.content", p)
 }
 
 func postContentList(ctx *hime.Context) error {
-	if ctx.FormValue("action") == "delete" {
-		contentID := ctx.FormValue("contentId")
+	id := ctx.FormValue("id")
+	contentID := ctx.FormValue("contentId")
+
+	switch ctx.FormValue("action") {
+	case "delete":
+		err := course.DeleteContent(ctx, id, contentID)
+		if err == course.ErrNotFound {
+			return ctx.RedirectToGet()
+		}
+		if err != nil {
+			return err
+		}
+	case "moveUp", "moveDown", "moveTo":
+		contents, err := course.GetContents(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		from := -1
+		ids := make([]string, len(contents))
+		for i, x := range contents {
+			ids[i] = x.ID
+			if x.ID == contentID {
+				from = i
+			}
+		}
+		if from < 0 {
+			return ctx.RedirectToGet()
+		}
+
+		// content can only move inside its section
+		lo, hi := from, from
+		for lo > 0 && contents[lo-1].SectionID == contents[from].SectionID {
+			lo--
+		}
+		for hi < len(contents)-1 && contents[hi+1].SectionID == contents[from].SectionID {
+			hi++
+		}
+
+		to := from
+		switch ctx.FormValue("action") {
+		case "moveUp":
+			to--
+		case "moveDown":
+			to++
+		case "moveTo":
+			// position is 1-based
+			to, _ = strconv.Atoi(ctx.FormValue("position"))
+			to += lo - 1
+		}
+		if to < lo {
+			to = lo
+		}
+		if to > hi {
+			to = hi
+		}
 
-		err := course.DeleteContent(ctx, contentID)
+		err = course.ReorderContents(ctx, id, moveID(ids, from, to))
+		if err == course.ErrInvalidContents {
+			return ctx.RedirectToGet()
+		}
 		if err != nil {
 			return err
 		}
@@ -44,6 +105,23 @@ func postContentList(ctx *hime.Context) error {
 	return ctx.RedirectToGet()
 }
 
+// moveID moves ids[from] to index to, and shifts the others
+func moveID(ids []string, from, to int) []string {
+	if to < 0 {
+		to = 0
+	}
+	if to > len(ids)-1 {
+		to = len(ids) - 1
+	}
+
+	x := ids[from]
+	rs := make([]string, 0, len(ids))
+	rs = append(rs, ids[:from]...)
+	rs = append(rs, ids[from+1:]...)
+	rs = append(rs[:to], append([]string{x}, rs[to:]...)...)
+	return rs
+}
+
 func getContentCreate(ctx *hime.Context) error {
 	id := ctx.FormValue("id")
 
@@ -55,27 +133,54 @@ func getContentCreate(ctx *hime.Context) error {
 		return err
 	}
 
+	sections, err := course.GetSections(ctx, id)
+	if err != nil {
+		return err
+	}
+
 	p := view.Page(ctx)
 	p.Data["Course"] = c
+	p.Data["Sections"] = sections
+	p.Data["SectionID"] = ctx.FormValue("sectionId")
 	return ctx.View("editor.content-create", p)
 }
 
 func postContentCreate(ctx *hime.Context) error {
 	id := ctx.FormValue("id")
 
+	f := appctx.GetFlash(ctx)
+
 	var (
-		title   = ctx.FormValue("title")
-		desc    = ctx.FormValue("desc")
-		videoID = ctx.FormValue("videoId")
+		sectionID    = ctx.PostFormValue("sectionId")
+		title        = ctx.FormValue("title")
+		desc         = ctx.FormValue("desc")
+		videoID      = ctx.FormValue("videoId")
+		videoType, _ = strconv.Atoi(ctx.PostFormValue("videoType"))
 	)
+	videoFile, _ := ctx.FormFileHeaderNotEmpty("videoFile")
+	release, releaseDays, releaseNotify := parseContentRelease(ctx)
 
 	_, err := course.CreateContent(ctx, &course.CreateContentArgs{
 		ID:        id,
+		SectionID: sectionID,
 		Title:     title,
 		LongDesc:  desc,
 		VideoID:   videoID,
-		VideoType: course.Youtube,
+		VideoType: videoType,
+		VideoFile: videoFile,
+
+		Release:       release,
+		ReleaseDays:   releaseDays,
+		ReleaseNotify: releaseNotify,
 	})
+	if err == course.ErrInvalidRelease {
+		f.Add("Errors", "กำหนดการเปิดให้เรียนไม่ถูกต้อง")
+		return ctx.RedirectToGet()
+	}
+	if msg := videoErrorMessage(err); msg != "" {
+		f.Add("Errors", msg)
+		return ctx.RedirectToGet()
+	}
 	if err != nil {
 		return err
 	}
@@ -100,15 +205,27 @@ func getContentEdit(ctx *hime.Context) error {
 		return err
 	}
 
-	user := appctx.GetUser(ctx)
-	// user is not course owner
-	if user.ID != c.Owner.ID {
-		return ctx.Status(http.StatusForbidden).StatusText()
+	sections, err := course.GetSections(ctx, c.ID)
+	if err != nil {
+		return err
+	}
+
+	attachments, err := course.GetAttachments(ctx, content.ID)
+	if err != nil {
+		return err
+	}
+
+	x, err := quiz.Get(ctx, content.ID)
+	if err != nil && err != quiz.ErrNotFound {
+		return err
 	}
 
 	p := view.Page(ctx)
 	p.Data["Course"] = c
 	p.Data["Content"] = content
+	p.Data["Sections"] = sections
+	p.Data["Attachments"] = attachments
+	p.Data["Quiz"] = x
 	return ctx.View("editor.content-edit", p)
 }
 
@@ -124,33 +241,102 @@ func postContentEdit(ctx *hime.Context) error {
 		return err
 	}
 
-	c, err := course.Get(ctx, content.CourseID)
-	if err != nil {
-		return err
-	}
-
-	user := appctx.GetUser(ctx)
-	// user is not course owner
-	// TODO: move to service
-	if user.ID != c.Owner.ID {
-		return ctx.Status(http.StatusForbidden).StatusText()
-	}
+	f := appctx.GetFlash(ctx)
 
 	var (
-		title   = ctx.FormValue("title")
-		desc    = ctx.FormValue("desc")
-		videoID = ctx.FormValue("videoId")
+		sectionID    = ctx.PostFormValue("sectionId")
+		title        = ctx.FormValue("title")
+		desc         = ctx.FormValue("desc")
+		videoID      = ctx.FormValue("videoId")
+		videoType, _ = strconv.Atoi(ctx.PostFormValue("videoType"))
 	)
+	videoFile, _ := ctx.FormFileHeaderNotEmpty("videoFile")
+	release, releaseDays, releaseNotify := parseContentRelease(ctx)
 
 	err = course.UpdateContent(ctx, &course.UpdateContentArgs{
 		ContentID: id,
+		SectionID: sectionID,
 		Title:     title,
 		Desc:      desc,
 		VideoID:   videoID,
+		VideoType: videoType,
+		VideoFile: videoFile,
+
+		Release:       release,
+		ReleaseDays:   releaseDays,
+		ReleaseNotify: releaseNotify,
 	})
+	if err == course.ErrInvalidRelease {
+		f.Add("Errors", "กำหนดการเปิดให้เรียนไม่ถูกต้อง")
+		return ctx.RedirectToGet()
+	}
+	if msg := videoErrorMessage(err); msg != "" {
+		f.Add("Errors", msg)
+		return ctx.RedirectToGet()
+	}
 	if err != nil {
 		return err
 	}
 
 	return ctx.RedirectTo("editor.content", ctx.Param("id", content.CourseID))
 }
+
+// parseContentRelease parses content's release rule from form
+func parseContentRelease(ctx *hime.Context) (release, days int, notify bool) {
+	release, _ = strconv.Atoi(ctx.PostFormValue("release"))
+	if release != course.ReleaseNow {
+		days, _ = strconv.Atoi(ctx.PostFormValue("releaseDays"))
+		notify = ctx.PostFormValue("releaseNotify") != ""
+	}
+	return
+}
+
+func postContentAttachment(ctx *hime.Context) error {
+	// course content id
+	id := ctx.FormValue("id")
+
+	f := appctx.GetFlash(ctx)
+
+	switch ctx.FormValue("action") {
+	case "upload":
+		err := ctx.ParseMultipartForm(32 << 20)
+		if err != nil {
+			f.Add("Errors", "กรุณาเลือกไฟล์")
+			break
+		}
+
+		for _, fh := range ctx.MultipartForm.File["attachments"] {
+			_, err = course.AddAttachment(ctx, id, fh)
+			if err == course.ErrAttachmentTooLarge {
+				f.Add("Errors", fmt.Sprintf("%s: ไฟล์ต้องมีขนาดไม่เกิน %s", fh.Filename, humanize.Bytes(course.MaxAttachmentSize)))
+				continue
+			}
+			if err == course.ErrInvalidAttachment {
+				f.Add("Errors", "กรุณาเลือกไฟล์")
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+	case "delete":
+		err := course.DeleteAttachment(ctx, id, ctx.FormValue("attachmentId"))
+		if err != nil {
+			return err
+		}
+	}
+
+	return ctx.RedirectTo("editor.content.edit", ctx.Param("id", id))
+}
+
+func videoErrorMessage(err error) string {
+	switch err {
+	case video.ErrInvalidID:
+		return "ลิงก์หรือ ID ของวิดีโอไม่ถูกต้อง"
+	case video.ErrInvalidType:
+		return "รองรับไฟล์วิดีโอ mp4 และ webm เท่านั้น"
+	case video.ErrUnknownProvider:
+		return "กรุณาเลือกประเภทวิดีโอ"
+	}
+	return ""
+}
@@ -1,11 +1,18 @@
 package editor
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/acoshift/pgsql/pgctx"
 	"github.com/moonrhythm/hime"
 
 	"github.com/acoshift/acourse/internal/app/view"
+	"github.com/acoshift/acourse/internal/pkg/bundle"
 	"github.com/acoshift/acourse/internal/pkg/context/appctx"
 	"github.com/acoshift/acourse/internal/pkg/course"
 	"github.com/acoshift/acourse/internal/pkg/image"
@@ -70,8 +77,15 @@ func getCourseEdit(ctx *hime.Context) error {
 		return err
 	}
 
+	categories, err := course.GetCategories(ctx)
+	if err != nil {
+		return err
+	}
+
 	p := view.Page(ctx)
 	p.Data["Course"] = c
+	p.Data["Categories"] = categories
+	p.Data["Role"] = getRole(ctx)
 	return ctx.View("editor.course-edit", p)
 }
 
@@ -81,10 +95,16 @@ func postCourseEdit(ctx *hime.Context) error {
 	f := appctx.GetFlash(ctx)
 
 	var (
-		title     = ctx.FormValue("title")
-		shortDesc = ctx.FormValue("shortDesc")
-		desc      = ctx.FormValue("desc")
-		start     time.Time
+		title        = ctx.FormValue("title")
+		shortDesc    = ctx.FormValue("shortDesc")
+		desc         = ctx.FormValue("desc")
+		start        time.Time
+		typ, _       = strconv.Atoi(ctx.FormValue("type"))
+		price, _     = strconv.ParseFloat(ctx.FormValue("price"), 64)
+		discount, _  = strconv.ParseFloat(ctx.FormValue("discount"), 64)
+		useDiscount  = ctx.FormValue("useDiscount") != ""
+		enrollDetail = ctx.FormValue("enrollDetail")
+		categoryID   = ctx.FormValue("categoryId")
 		// assignment, _ = strconv.ParseBool(ctx.FormValue("assignment"))
 	)
 	if len(title) == 0 {
@@ -96,20 +116,74 @@ func postCourseEdit(ctx *hime.Context) error {
 		start, _ = time.Parse("2006-01-02", v)
 	}
 
+	// only member who can manage the course can change type and pricing
+	if !getRole(ctx).Can(course.Manage) {
+		c, err := course.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		typ = c.Type
+		price = c.Price
+		discount = c.Discount
+		useDiscount = c.Option.Discount
+		enrollDetail = c.EnrollDetail
+	}
+
+	tags := course.ParseTags(ctx.FormValue("tags"))
+	if !course.ValidTags(tags) {
+		f.Add("Errors", fmt.Sprintf("ใส่แท็กได้ไม่เกิน %d แท็ก แต่ละแท็กยาวไม่เกิน %d ตัวอักษร", course.MaxTags, course.MaxTagLength))
+		return ctx.RedirectToGet()
+	}
+
 	img, _ := ctx.FormFileHeaderNotEmpty("image")
 
-	err := course.Update(ctx, &course.UpdateArgs{
-		ID:        id,
-		Title:     title,
-		ShortDesc: shortDesc,
-		LongDesc:  desc,
-		Image:     img,
-		Start:     start,
+	// update course, tags and category together, so invalid input does not leave the course partially updated
+	err := pgctx.RunInTx(ctx, func(ctx context.Context) error {
+		// update validates its args before writing
+		err := course.Update(ctx, &course.UpdateArgs{
+			ID:           id,
+			Title:        title,
+			ShortDesc:    shortDesc,
+			LongDesc:     desc,
+			Image:        img,
+			Start:        start,
+			Type:         typ,
+			Price:        price,
+			Discount:     discount,
+			UseDiscount:  useDiscount,
+			EnrollDetail: enrollDetail,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = course.SetTags(ctx, id, tags)
+		if err != nil {
+			return err
+		}
+
+		return course.SetCategory(ctx, id, categoryID)
 	})
+	if err == course.ErrInvalidCategory {
+		f.Add("Errors", "หมวดหมู่ไม่ถูกต้อง")
+		return ctx.RedirectToGet()
+	}
 	if err == image.ErrInvalidType {
 		f.Add("Errors", "รองรับไฟล์ jpeg และ png เท่านั้น")
 		return ctx.RedirectToGet()
 	}
+	if err == course.ErrInvalidType {
+		f.Add("Errors", "กรุณาเลือกประเภทคอร์ส")
+		return ctx.RedirectToGet()
+	}
+	if err == course.ErrInvalidPrice {
+		f.Add("Errors", fmt.Sprintf("ราคาต้องไม่ติดลบและไม่เกิน %.2f บาท", course.MaxPrice))
+		return ctx.RedirectToGet()
+	}
+	if err == course.ErrInvalidDiscount {
+		f.Add("Errors", "ราคาส่วนลดต้องไม่ติดลบและไม่เกินราคาคอร์ส")
+		return ctx.RedirectToGet()
+	}
 	if err != nil {
 		f.Add("Errors", err.Error())
 		return ctx.RedirectToGet()
@@ -121,3 +195,239 @@ func postCourseEdit(ctx *hime.Context) error {
 	}
 	return ctx.RedirectTo("app.course", link)
 }
+
+func getCourseSetting(ctx *hime.Context) error {
+	id := ctx.FormValue("id")
+	c, err := course.Get(ctx, id)
+	if err == course.ErrNotFound {
+		return view.NotFound(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	members, err := course.GetMembers(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	prerequisites, err := course.GetPrerequisites(ctx, id, "")
+	if err != nil {
+		return err
+	}
+
+	p := view.Page(ctx)
+	p.Data["Course"] = c
+	p.Data["Members"] = members
+	p.Data["Prerequisites"] = prerequisites
+	return ctx.View("editor.course-setting", p)
+}
+
+func postCourseSetting(ctx *hime.Context) error {
+	id := ctx.FormValue("id")
+
+	f := appctx.GetFlash(ctx)
+
+	switch ctx.FormValue("action") {
+	case "option":
+		c, err := course.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// discount option is managed in course edit page
+		err = course.SetOption(ctx, id, course.Option{
+			Public:     ctx.PostFormValue("public") != "",
+			Enroll:     ctx.PostFormValue("enroll") != "",
+			Attend:     ctx.PostFormValue("attend") != "",
+			Assignment: ctx.PostFormValue("assignment") != "",
+			Discount:   c.Option.Discount,
+		})
+		if err != nil {
+			return err
+		}
+	case "url":
+		url := strings.ToLower(ctx.PostFormValueTrimSpace("url"))
+
+		err := course.SetURL(ctx, id, url)
+		if err == course.ErrInvalidURL {
+			f.Add("Errors", "URL ต้องยาว 3-64 ตัวอักษร ประกอบด้วย a-z, 0-9 และ - เท่านั้น")
+			return ctx.RedirectToGet()
+		}
+		if err == course.ErrURLNotAvailable {
+			f.Add("Errors", "URL นี้ถูกใช้แล้ว")
+			return ctx.RedirectToGet()
+		}
+		if err != nil {
+			return err
+		}
+	case "addMember":
+		role, _ := strconv.Atoi(ctx.PostFormValue("role"))
+
+		err := course.AddMember(ctx, id, ctx.PostFormValueTrimSpace("username"), course.Role(role))
+		if err == course.ErrUserNotFound {
+			f.Add("Errors", "ไม่พบผู้ใช้นี้")
+			return ctx.RedirectToGet()
+		}
+		if err == course.ErrAlreadyMember {
+			f.Add("Errors", "ผู้ใช้นี้เป็นผู้สอนของคอร์สอยู่แล้ว")
+			return ctx.RedirectToGet()
+		}
+		if err == course.ErrInvalidRole {
+			f.Add("Errors", "ตำแหน่งไม่ถูกต้อง")
+			return ctx.RedirectToGet()
+		}
+		if err != nil {
+			return err
+		}
+	case "memberRole":
+		role, _ := strconv.Atoi(ctx.PostFormValue("role"))
+
+		err := course.SetMemberRole(ctx, id, ctx.PostFormValue("userId"), course.Role(role))
+		if err == course.ErrInvalidRole {
+			f.Add("Errors", "ตำแหน่งไม่ถูกต้อง")
+			return ctx.RedirectToGet()
+		}
+		if err != nil && err != course.ErrMemberNotFound {
+			return err
+		}
+	case "removeMember":
+		err := course.RemoveMember(ctx, id, ctx.PostFormValue("userId"))
+		if err != nil && err != course.ErrMemberNotFound {
+			return err
+		}
+	case "addPrerequisite":
+		err := course.AddPrerequisite(ctx, id, ctx.PostFormValue("course"), ctx.PostFormValue("required") != "")
+		if err == course.ErrNotFound {
+			f.Add("Errors", "ไม่พบคอร์สนี้")
+			return ctx.RedirectToGet()
+		}
+		if err == course.ErrInvalidPrerequisite {
+			f.Add("Errors", "ไม่สามารถเพิ่มคอร์สนี้เป็นคอร์สที่ต้องเรียนก่อนได้ เนื่องจากเป็นคอร์สเดียวกันหรือต้องเรียนคอร์สนี้ก่อน")
+			return ctx.RedirectToGet()
+		}
+		if err != nil {
+			return err
+		}
+	case "removePrerequisite":
+		err := course.RemovePrerequisite(ctx, id, ctx.PostFormValue("courseId"))
+		if err != nil {
+			return err
+		}
+	case "duplicate":
+		start, _ := time.Parse("2006-01-02", ctx.PostFormValue("start"))
+
+		newID, err := course.Clone(ctx, id, appctx.GetUserID(ctx), start)
+		if err == course.ErrStartRequired {
+			f.Add("Errors", "กรุณาระบุวันที่เริ่มเรียนของคอร์สใหม่")
+			return ctx.RedirectToGet()
+		}
+		if err != nil {
+			return err
+		}
+
+		return ctx.RedirectTo("editor.course.edit", ctx.Param("id", newID))
+	case "archive":
+		err := course.Archive(ctx, id)
+		if err != nil {
+			return err
+		}
+	case "unarchive":
+		err := course.Unarchive(ctx, id)
+		if err != nil {
+			return err
+		}
+	case "delete":
+		err := course.Delete(ctx, id)
+		if err == course.ErrHasEnrolls {
+			f.Add("Errors", "ไม่สามารถลบคอร์สที่มีผู้สมัครเรียนแล้วได้ กรุณาเก็บถาวรคอร์สแทน")
+			return ctx.RedirectToGet()
+		}
+		if err != nil && err != course.ErrNotFound {
+			return err
+		}
+
+		return ctx.RedirectTo("app.profile")
+	}
+
+	return ctx.RedirectToGet()
+}
+
+func getCourseExport(ctx *hime.Context) error {
+	id := ctx.FormValue("id")
+
+	var buf bytes.Buffer
+	err := bundle.Export(ctx, &buf, id)
+	if err == course.ErrNotFound {
+		return view.NotFound(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	ctx.SetHeader("Content-Type", "application/zip")
+	ctx.SetHeader("Content-Disposition", `attachment; filename="course-`+id+`.zip"`)
+	return ctx.Bytes(buf.Bytes())
+}
+
+func postCourseImport(ctx *hime.Context) error {
+	f := appctx.GetFlash(ctx)
+
+	fh, err := ctx.FormFileHeaderNotEmpty("file")
+	if err != nil {
+		f.Add("Errors", "กรุณาเลือกไฟล์")
+		return ctx.RedirectTo("editor.course.create")
+	}
+
+	fp, err := fh.Open()
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+
+	x, err := bundle.Read(fp, fh.Size)
+	if err == bundle.ErrInvalidBundle {
+		f.Add("Errors", "ไฟล์ไม่ถูกต้อง")
+		return ctx.RedirectTo("editor.course.create")
+	}
+	if err == bundle.ErrUnsupportedVersion {
+		f.Add("Errors", "ไม่รองรับไฟล์เวอร์ชันนี้")
+		return ctx.RedirectTo("editor.course.create")
+	}
+	if err != nil {
+		return err
+	}
+
+	r, err := bundle.Import(ctx, x, appctx.GetUserID(ctx))
+	if err != nil {
+		f.Add("Errors", err.Error())
+		return ctx.RedirectTo("editor.course.create")
+	}
+
+	for _, c := range r.Conflicts {
+		f.Add("Errors", "นำเข้าคอร์สแล้ว แต่พบปัญหา: "+c)
+	}
+
+	return ctx.RedirectTo("editor.course.setting", ctx.Param("id", r.CourseID))
+}
+
+func getCourseStudent(ctx *hime.Context) error {
+	id := ctx.FormValue("id")
+	c, err := course.Get(ctx, id)
+	if err == course.ErrNotFound {
+		return view.NotFound(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	students, err := course.GetStudents(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	p := view.Page(ctx)
+	p.Data["Course"] = c
+	p.Data["Students"] = students
+	return ctx.View("editor.course-student", p)
+}
@@ -1,6 +1,7 @@
 package editor
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/acoshift/methodmux"
@@ -19,25 +20,62 @@ func Mount(m *httpmux.Mux) {
 		hime.Handler(getCourseCreate),
 		hime.Handler(postCourseCreate),
 	))
+	instructorMux.Handle("/course/import", methodmux.Post(
+		hime.Handler(postCourseImport),
+	))
 
-	courseOwnerMux := m.Group("/editor", onlyCourseOwner)
-	courseOwnerMux.Handle("/course/edit", methodmux.GetPost(
+	courseEditMux := m.Group("/editor", onlyCourseMember(course.EditContent))
+	courseEditMux.Handle("/course/edit", methodmux.GetPost(
 		hime.Handler(getCourseEdit),
 		hime.Handler(postCourseEdit),
 	))
-	courseOwnerMux.Handle("/content", methodmux.GetPost(
+	courseEditMux.Handle("/content", methodmux.GetPost(
 		hime.Handler(getContentList),
 		hime.Handler(postContentList),
 	))
-	courseOwnerMux.Handle("/content/create", methodmux.GetPost(
+	courseEditMux.Handle("/content/create", methodmux.GetPost(
 		hime.Handler(getContentCreate),
 		hime.Handler(postContentCreate),
 	))
+	courseEditMux.Handle("/section", methodmux.GetPost(
+		hime.Handler(getSectionList),
+		hime.Handler(postSectionList),
+	))
+
+	courseManageMux := m.Group("/editor", onlyCourseMember(course.Manage))
+	courseManageMux.Handle("/course/setting", methodmux.GetPost(
+		hime.Handler(getCourseSetting),
+		hime.Handler(postCourseSetting),
+	))
+	courseManageMux.Handle("/course/export", methodmux.Get(
+		hime.Handler(getCourseExport),
+	))
+
+	courseGradeMux := m.Group("/editor", onlyCourseMember(course.Grade))
+	courseGradeMux.Handle("/assignment", methodmux.GetPost(
+		hime.Handler(getAssignmentList),
+		hime.Handler(postAssignmentList),
+	))
+	courseGradeMux.Handle("/assignment/submission", methodmux.GetPost(
+		hime.Handler(getAssignmentSubmission),
+		hime.Handler(postAssignmentSubmission),
+	))
 
-	m.Handle("/editor/content/edit", onlyCourseContentOwner(methodmux.GetPost(
+	courseRosterMux := m.Group("/editor", onlyCourseMember(course.ViewRoster))
+	courseRosterMux.Handle("/course/student", methodmux.Get(
+		hime.Handler(getCourseStudent),
+	))
+
+	m.Handle("/editor/content/edit", onlyCourseContentMember(course.EditContent)(methodmux.GetPost(
 		hime.Handler(getContentEdit),
 		hime.Handler(postContentEdit),
 	)))
+	m.Handle("/editor/content/attachment", onlyCourseContentMember(course.EditContent)(methodmux.Post(
+		hime.Handler(postContentAttachment),
+	)))
+	m.Handle("/editor/content/quiz", onlyCourseContentMember(course.EditContent)(methodmux.Post(
+		hime.Handler(postContentQuiz),
+	)))
 }
 
 func onlyInstructor(h http.Handler) http.Handler {
@@ -55,57 +93,57 @@ func onlyInstructor(h http.Handler) http.Handler {
 	})
 }
 
-func onlyCourseOwner(h http.Handler) http.Handler {
-	return hime.Handler(func(ctx *hime.Context) error {
-		u := appctx.GetUser(ctx)
-		if u == nil {
-			return ctx.RedirectTo("auth.signin")
-		}
+type roleKey struct{}
+
+// getRole gets current user's role in the course from context
+func getRole(ctx context.Context) course.Role {
+	r, _ := ctx.Value(roleKey{}).(course.Role)
+	return r
+}
 
-		id := ctx.FormValue("id")
+// authorize checks is current user has permission in the course,
+// then stores user's role in context
+func authorize(ctx *hime.Context, courseID string, p course.Permission, h http.Handler) error {
+	u := appctx.GetUser(ctx)
+	if u == nil {
+		return ctx.RedirectTo("auth.signin")
+	}
 
-		ownerID, err := course.GetUserID(ctx, id)
-		if err == course.ErrNotFound {
-			return view.NotFound(ctx)
-		}
-		if err != nil {
-			return err
-		}
+	r, err := course.GetRole(ctx, courseID, u.ID)
+	if err == course.ErrNotFound {
+		return view.NotFound(ctx)
+	}
+	if err != nil {
+		return err
+	}
 
-		if ownerID != u.ID {
-			return ctx.Redirect("/")
-		}
-		return ctx.Handle(h)
-	})
+	if !r.Can(p) {
+		return ctx.Redirect("/")
+	}
+	return ctx.WithValue(roleKey{}, r).Handle(h)
 }
 
-func onlyCourseContentOwner(h http.Handler) http.Handler {
-	return hime.Handler(func(ctx *hime.Context) error {
-		u := appctx.GetUser(ctx)
-		if u == nil {
-			return ctx.RedirectTo("auth.signin")
-		}
-
-		contentID := ctx.FormValue("id")
-		courseID, err := course.GetIDFromContent(ctx, contentID)
-		if err == course.ErrNotFound {
-			return view.NotFound(ctx)
-		}
-		if err != nil {
-			return err
-		}
+func onlyCourseMember(p course.Permission) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return hime.Handler(func(ctx *hime.Context) error {
+			return authorize(ctx, ctx.FormValue("id"), p, h)
+		})
+	}
+}
 
-		ownerID, err := course.GetUserID(ctx, courseID)
-		if err == course.ErrNotFound {
-			return view.NotFound(ctx)
-		}
-		if err != nil {
-			return err
-		}
+func onlyCourseContentMember(p course.Permission) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return hime.Handler(func(ctx *hime.Context) error {
+			contentID := ctx.FormValue("id")
+			courseID, err := course.GetIDFromContent(ctx, contentID)
+			if err == course.ErrNotFound {
+				return view.NotFound(ctx)
+			}
+			if err != nil {
+				return err
+			}
 
-		if ownerID != u.ID {
-			return ctx.Redirect("/")
-		}
-		return ctx.Handle(h)
-	})
+			return authorize(ctx, courseID, p, h)
+		})
+	}
 }
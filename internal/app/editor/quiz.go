@@ -0,0 +1,88 @@
+package editor
+
+import (
+	"strconv"
+
+	"github.com/moonrhythm/hime"
+
+	"github.com/acoshift/acourse/internal/pkg/context/appctx"
+	"github.com/acoshift/acourse/internal/pkg/quiz"
+)
+
+func postContentQuiz(ctx *hime.Context) error {
+	// course content id
+	id := ctx.FormValue("id")
+
+	f := appctx.GetFlash(ctx)
+
+	switch ctx.FormValue("action") {
+	case "save":
+		passMark, _ := strconv.Atoi(ctx.PostFormValue("passMark"))
+		maxAttempts, _ := strconv.Atoi(ctx.PostFormValue("maxAttempts"))
+
+		_, err := quiz.Save(ctx, &quiz.SaveArgs{
+			ContentID:   id,
+			Title:       ctx.PostFormValueTrimSpace("title"),
+			PassMark:    passMark,
+			MaxAttempts: maxAttempts,
+		})
+		if err == quiz.ErrInvalidQuiz {
+			f.Add("Errors", "เกณฑ์ผ่านต้องอยู่ระหว่าง 0-100 และจำนวนครั้งต้องไม่ติดลบ")
+			break
+		}
+		if err != nil {
+			return err
+		}
+	case "delete":
+		err := quiz.Delete(ctx, id)
+		if err != nil {
+			return err
+		}
+	case "addQuestion", "updateQuestion":
+		x, err := quiz.Get(ctx, id)
+		if err == quiz.ErrNotFound {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		q := quiz.Question{
+			ID:       ctx.PostFormValue("questionId"),
+			QuizID:   x.ID,
+			Question: ctx.PostFormValueTrimSpace("question"),
+			Answers:  quiz.ParseAnswers(ctx.PostFormValue("answers")),
+		}
+		q.Type, _ = strconv.Atoi(ctx.PostFormValue("type"))
+		q.Match, _ = strconv.Atoi(ctx.PostFormValue("match"))
+		q.Choices, q.Corrects = quiz.ParseChoices(ctx.PostFormValue("choices"))
+
+		if ctx.FormValue("action") == "addQuestion" {
+			_, err = quiz.AddQuestion(ctx, &q)
+		} else {
+			err = quiz.UpdateQuestion(ctx, &q)
+		}
+		if err == quiz.ErrInvalidQuestion {
+			f.Add("Errors", "คำถามไม่ถูกต้อง กรุณาใส่ตัวเลือกอย่างน้อย 2 ข้อและเลือกคำตอบที่ถูกต้อง หรือใส่คำตอบของคำถามแบบเติมคำ")
+			break
+		}
+		if err != nil && err != quiz.ErrNotFound {
+			return err
+		}
+	case "deleteQuestion":
+		x, err := quiz.Get(ctx, id)
+		if err == quiz.ErrNotFound {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		err = quiz.DeleteQuestion(ctx, x.ID, ctx.PostFormValue("questionId"))
+		if err != nil {
+			return err
+		}
+	}
+
+	return ctx.RedirectTo("editor.content.edit", ctx.Param("id", id))
+}
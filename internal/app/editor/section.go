@@ -0,0 +1,110 @@
+package editor
+
+import (
+	"github.com/moonrhythm/hime"
+
+	"github.com/acoshift/acourse/internal/app/view"
+	"github.com/acoshift/acourse/internal/pkg/context/appctx"
+	"github.com/acoshift/acourse/internal/pkg/course"
+)
+
+func getSectionList(ctx *hime.Context) error {
+	id := ctx.FormValue("id")
+
+	c, err := course.Get(ctx, id)
+	if err == course.ErrNotFound {
+		return view.NotFound(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	sections, err := course.GetSections(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	p := view.Page(ctx)
+	p.Data["Course"] = c
+	p.Data["Sections"] = sections
+	return ctx.View("editor.section", p)
+}
+
+func postSectionList(ctx *hime.Context) error {
+	id := ctx.FormValue("id")
+	sectionID := ctx.FormValue("sectionId")
+
+	f := appctx.GetFlash(ctx)
+
+	var (
+		title = ctx.PostFormValueTrimSpace("title")
+		desc  = ctx.PostFormValue("desc")
+	)
+
+	switch ctx.FormValue("action") {
+	case "create":
+		_, err := course.CreateSection(ctx, &course.CreateSectionArgs{
+			CourseID: id,
+			Title:    title,
+			Desc:     desc,
+		})
+		if err != nil {
+			f.Add("Errors", err.Error())
+			return ctx.RedirectToGet()
+		}
+	case "update":
+		err := course.UpdateSection(ctx, &course.UpdateSectionArgs{
+			CourseID:  id,
+			SectionID: sectionID,
+			Title:     title,
+			Desc:      desc,
+		})
+		if err == course.ErrNotFound {
+			return ctx.RedirectToGet()
+		}
+		if err != nil {
+			f.Add("Errors", err.Error())
+			return ctx.RedirectToGet()
+		}
+	case "delete":
+		err := course.DeleteSection(ctx, id, sectionID)
+		if err != nil {
+			return err
+		}
+	case "moveUp", "moveDown":
+		sections, err := course.GetSections(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		from := -1
+		var ids []string
+		for _, x := range sections {
+			// skip contents without section
+			if x.ID == "" {
+				continue
+			}
+			if x.ID == sectionID {
+				from = len(ids)
+			}
+			ids = append(ids, x.ID)
+		}
+		if from < 0 {
+			return ctx.RedirectToGet()
+		}
+
+		to := from - 1
+		if ctx.FormValue("action") == "moveDown" {
+			to = from + 1
+		}
+
+		err = course.ReorderSections(ctx, id, moveID(ids, from, to))
+		if err == course.ErrInvalidSections {
+			return ctx.RedirectToGet()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return ctx.RedirectToGet()
+}
@@ -2,14 +2,20 @@ package app
 
 import (
 	"html/template"
+	"strings"
 	"time"
 
 	"github.com/dustin/go-humanize"
 
 	"github.com/acoshift/acourse/internal/pkg/config"
 	"github.com/acoshift/acourse/internal/pkg/course"
+	"github.com/acoshift/acourse/internal/pkg/discussion"
 	"github.com/acoshift/acourse/internal/pkg/markdown"
 	"github.com/acoshift/acourse/internal/pkg/payment"
+	"github.com/acoshift/acourse/internal/pkg/quiz"
+	"github.com/acoshift/acourse/internal/pkg/review"
+	"github.com/acoshift/acourse/internal/pkg/search"
+	"github.com/acoshift/acourse/internal/pkg/video"
 )
 
 func templateFunc() template.FuncMap {
@@ -29,6 +35,18 @@ func templateFunc() template.FuncMap {
 				return ""
 			}
 		},
+		"courseRole": func(v course.Role) string {
+			switch v {
+			case course.RoleOwner:
+				return "เจ้าของคอร์ส"
+			case course.RoleInstructor:
+				return "ผู้สอนร่วม"
+			case course.RoleAssistant:
+				return "ผู้ช่วยสอน"
+			default:
+				return ""
+			}
+		},
 		"date": func(v time.Time) string {
 			return v.In(config.Location()).Format("02/01/2006")
 		},
@@ -38,7 +56,17 @@ func templateFunc() template.FuncMap {
 		"dateInput": func(v time.Time) string {
 			return v.Format("2006-01-02")
 		},
-		"markdown": markdown.HTML,
+		"dateTimeInput": func(v time.Time) string {
+			if v.IsZero() {
+				return ""
+			}
+			return v.In(config.Location()).Format("2006-01-02T15:04")
+		},
+		"isoTime": func(v time.Time) string {
+			return v.Format(time.RFC3339)
+		},
+		"markdown":  markdown.HTML,
+		"highlight": search.Highlight,
 		"live": func() int {
 			return course.Live
 		},
@@ -63,11 +91,77 @@ func templateFunc() template.FuncMap {
 		"html": func(v string) template.HTML {
 			return template.HTML(v)
 		},
+		"join": strings.Join,
+		"stars": func(n int) string {
+			return strings.Repeat("★", n) + strings.Repeat("☆", review.MaxRating-n)
+		},
+		"comment": func(page map[string]interface{}, c *discussion.Comment) map[string]interface{} {
+			return map[string]interface{}{
+				"Page":    page,
+				"Comment": c,
+			}
+		},
+		"ratings": func() []int {
+			var xs []int
+			for i := review.MaxRating; i >= review.MinRating; i-- {
+				xs = append(xs, i)
+			}
+			return xs
+		},
 		"incr": func(v int) int {
 			return v + 1
 		},
 		"fallbackImage": func() string {
 			return "/-/placeholder-img.svg"
 		},
+		"videoProviders": video.Providers,
+		"videoFile": func() int {
+			return video.File
+		},
+		"roleOwner": func() course.Role {
+			return course.RoleOwner
+		},
+		"roleInstructor": func() course.Role {
+			return course.RoleInstructor
+		},
+		"roleAssistant": func() course.Role {
+			return course.RoleAssistant
+		},
+		"permEditContent": func() course.Permission {
+			return course.EditContent
+		},
+		"permGrade": func() course.Permission {
+			return course.Grade
+		},
+		"permViewRoster": func() course.Permission {
+			return course.ViewRoster
+		},
+		"permManage": func() course.Permission {
+			return course.Manage
+		},
+		"releaseNow": func() int {
+			return course.ReleaseNow
+		},
+		"releaseEnroll": func() int {
+			return course.ReleaseEnroll
+		},
+		"releaseStart": func() int {
+			return course.ReleaseStart
+		},
+		"quizSingleChoice": func() int {
+			return quiz.SingleChoice
+		},
+		"quizMultipleChoice": func() int {
+			return quiz.MultipleChoice
+		},
+		"quizText": func() int {
+			return quiz.Text
+		},
+		"quizRegex": func() int {
+			return quiz.Regex
+		},
+		"quizIgnoreCase": func() int {
+			return quiz.IgnoreCase
+		},
 	}
 }
@@ -0,0 +1,34 @@
+package view
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/acoshift/paginate"
+	"github.com/moonrhythm/hime"
+)
+
+// Pagination is paginate which keeps request's query in page links
+type Pagination struct {
+	*paginate.Paginate
+
+	query url.Values
+}
+
+// Paginate creates pagination for current request
+func Paginate(ctx *hime.Context, pn *paginate.Paginate) *Pagination {
+	return &Pagination{
+		Paginate: pn,
+		query:    ctx.URL.Query(),
+	}
+}
+
+// URL returns link to page
+func (p *Pagination) URL(page int64) string {
+	q := make(url.Values)
+	for k, v := range p.query {
+		q[k] = v
+	}
+	q.Set("page", strconv.FormatInt(page, 10))
+	return "?" + q.Encode()
+}
@@ -11,17 +11,18 @@ import (
 )
 
 type Course struct {
-	ID        string
-	Title     string
-	Image     string
-	Type      int
-	Price     float64
-	Discount  float64
-	URL       string
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	Option    course.Option
-	Owner     struct {
+	ID         string
+	Title      string
+	Image      string
+	Type       int
+	Price      float64
+	Discount   float64
+	URL        string
+	ArchivedAt time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	Option     course.Option
+	Owner      struct {
 		ID       string
 		Username string
 		Image    string
@@ -34,7 +35,7 @@ func GetCourses(ctx context.Context, limit, offset int64) ([]*Course, error) {
 		select
 			c.id, c.title, c.image,
 			c.url, c.type, c.price, c.discount,
-			c.created_at, c.updated_at,
+			c.archived_at, c.created_at, c.updated_at,
 			opt.public, opt.enroll, opt.attend, opt.assignment, opt.discount,
 			u.id, u.username, u.image
 		from courses as c
@@ -54,7 +55,7 @@ func GetCourses(ctx context.Context, limit, offset int64) ([]*Course, error) {
 		err = rows.Scan(
 			&x.ID, &x.Title, &x.Image,
 			pgsql.NullString(&x.URL), &x.Type, &x.Price, &x.Discount,
-			&x.CreatedAt, &x.UpdatedAt,
+			pgsql.NullTime(&x.ArchivedAt), &x.CreatedAt, &x.UpdatedAt,
 			&x.Option.Public, &x.Option.Enroll, &x.Option.Attend, &x.Option.Assignment, &x.Option.Discount,
 			&x.Owner.ID, &x.Owner.Username, &x.Owner.Image,
 		)
@@ -0,0 +1,84 @@
+package admin
+
+import (
+	"context"
+	"time"
+
+	"github.com/acoshift/pgsql"
+	"github.com/acoshift/pgsql/pgctx"
+)
+
+type Review struct {
+	Course struct {
+		ID    string
+		Title string
+		URL   string
+	}
+	User struct {
+		ID       string
+		Username string
+		Image    string
+	}
+	Rating    int
+	Review    string
+	Reply     string
+	Hidden    bool
+	Flagged   bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Link returns link to review's course
+func (x *Review) Link() string {
+	if x.Course.URL != "" {
+		return x.Course.URL
+	}
+	return x.Course.ID
+}
+
+// GetReviews gets reviews, flagged reviews first
+func GetReviews(ctx context.Context, limit, offset int64) ([]*Review, error) {
+	// language=SQL
+	rows, err := pgctx.Query(ctx, `
+		select
+			c.id, c.title, c.url,
+			u.id, u.username, u.image,
+			r.rating, r.review, r.reply, r.hidden, r.flagged,
+			r.created_at, r.updated_at
+		from reviews as r
+			left join courses as c on c.id = r.course_id
+			left join users as u on u.id = r.user_id
+		order by r.flagged desc, r.created_at desc
+		limit $1 offset $2
+	`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var xs []*Review
+	for rows.Next() {
+		var x Review
+		err = rows.Scan(
+			&x.Course.ID, &x.Course.Title, pgsql.NullString(&x.Course.URL),
+			&x.User.ID, &x.User.Username, &x.User.Image,
+			&x.Rating, &x.Review, &x.Reply, &x.Hidden, &x.Flagged,
+			&x.CreatedAt, &x.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		xs = append(xs, &x)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return xs, nil
+}
+
+func CountReviews(ctx context.Context) (cnt int64, err error) {
+	// language=SQL
+	err = pgctx.QueryRow(ctx, `select count(*) from reviews`).Scan(&cnt)
+	return
+}
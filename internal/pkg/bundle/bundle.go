@@ -0,0 +1,349 @@
+package bundle
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Version is the current bundle format version,
+// version 1 bundles have no stored files and still can be read
+const Version = 2
+
+// maxFileSize limits size of each file read from bundle
+const maxFileSize = 20 << 20
+
+// maxStoredFileSize limits size of each stored file in bundle,
+// larger stored files are kept as links to the source storage
+const maxStoredFileSize = 100 << 20
+
+// fileRef prefixes a reference to stored file in bundle, e.g. "bundle:files/001.png"
+const fileRef = "bundle:"
+
+// filesDir is the directory of stored files in bundle
+const filesDir = "files/"
+
+// Course is a portable course,
+// course.json keeps metadata while descriptions are kept as markdown files
+// and cover image is kept as cover.jpg.
+//
+// Files in the storage which are used by the course, i.e. attachments, uploaded videos
+// and images or files referenced in descriptions' markdown are kept in files directory,
+// and their urls are replaced by file references.
+type Course struct {
+	Version      int               `json:"version"`
+	Title        string            `json:"title"`
+	ShortDesc    string            `json:"shortDesc"`
+	Desc         string            `json:"-"`
+	EnrollDetail string            `json:"-"`
+	Image        []byte            `json:"-"`
+	Files        map[string][]byte `json:"-"` // stored files mapped by name in bundle
+	Start        time.Time         `json:"start"`
+	URL          string            `json:"url"`
+	Category     string            `json:"category,omitempty"`
+	Tags         []string          `json:"tags"`
+	Type         int               `json:"type"`
+	Price        float64           `json:"price"`
+	Discount     float64           `json:"discount"`
+	Option       Option            `json:"option"`
+	Sections     []*Section        `json:"sections"`
+	Assignments  []*Assignment     `json:"assignments"`
+}
+
+// Option is course option
+type Option struct {
+	Public     bool `json:"public"`
+	Enroll     bool `json:"enroll"`
+	Attend     bool `json:"attend"`
+	Assignment bool `json:"assignment"`
+	Discount   bool `json:"discount"`
+}
+
+// Section is a course section, section with empty title holds contents without section
+type Section struct {
+	Title    string     `json:"title"`
+	Desc     string     `json:"-"`
+	Contents []*Content `json:"contents"`
+}
+
+// Content is a course content
+type Content struct {
+	Title       string        `json:"title"`
+	Desc        string        `json:"-"`
+	VideoID     string        `json:"videoId"`
+	VideoType   int           `json:"videoType"`
+	Attachments []*Attachment `json:"attachments"`
+	Quiz        *Quiz         `json:"quiz,omitempty"`
+
+	Release       int  `json:"release,omitempty"`
+	ReleaseDays   int  `json:"releaseDays,omitempty"`
+	ReleaseNotify bool `json:"releaseNotify,omitempty"`
+}
+
+// Attachment is a content's attachment file,
+// download url is a file reference when the file is kept in bundle
+type Attachment struct {
+	Filename    string `json:"filename"`
+	Size        int64  `json:"size"`
+	DownloadURL string `json:"downloadUrl"`
+}
+
+// Quiz is a content's quiz
+type Quiz struct {
+	Title       string      `json:"title"`
+	PassMark    int         `json:"passMark"`
+	MaxAttempts int         `json:"maxAttempts"`
+	Questions   []*Question `json:"questions"`
+}
+
+// Question is a quiz's question
+type Question struct {
+	Type     int      `json:"type"`
+	Question string   `json:"question"`
+	Choices  []string `json:"choices"`
+	Corrects []int64  `json:"corrects"`
+	Answers  []string `json:"answers"`
+	Match    int      `json:"match"`
+}
+
+// Assignment is a course assignment
+type Assignment struct {
+	Title    string    `json:"title"`
+	Desc     string    `json:"-"`
+	Open     bool      `json:"open"`
+	Optional bool      `json:"optional,omitempty"`
+	DueAt    time.Time `json:"dueAt"`
+	DueDays  int       `json:"dueDays"`
+	LateDays int       `json:"lateDays"`
+}
+
+func sectionFile(i int) string {
+	return fmt.Sprintf("sections/%03d/section.md", i+1)
+}
+
+func contentFile(i, j int) string {
+	return fmt.Sprintf("sections/%03d/%03d.md", i+1, j+1)
+}
+
+func assignmentFile(i int) string {
+	return fmt.Sprintf("assignments/%03d.md", i+1)
+}
+
+// Write writes course as zip bundle into w
+func Write(w io.Writer, x *Course) error {
+	zw := zip.NewWriter(w)
+
+	write := func(name string, b []byte) error {
+		fw, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = fw.Write(b)
+		return err
+	}
+
+	x.Version = Version
+	b, err := json.MarshalIndent(x, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = write("course.json", b)
+	if err != nil {
+		return err
+	}
+	err = write("course.md", []byte(x.Desc))
+	if err != nil {
+		return err
+	}
+	err = write("enroll.md", []byte(x.EnrollDetail))
+	if err != nil {
+		return err
+	}
+	if len(x.Image) > 0 {
+		err = write("cover.jpg", x.Image)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, s := range x.Sections {
+		err = write(sectionFile(i), []byte(s.Desc))
+		if err != nil {
+			return err
+		}
+
+		for j, c := range s.Contents {
+			err = write(contentFile(i, j), []byte(c.Desc))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, a := range x.Assignments {
+		err = write(assignmentFile(i), []byte(a.Desc))
+		if err != nil {
+			return err
+		}
+	}
+
+	names := make([]string, 0, len(x.Files))
+	for name := range x.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !validFileName(name) {
+			return ErrInvalidBundle
+		}
+		err = write(name, x.Files[name])
+		if err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// Read reads course from zip bundle
+func Read(r io.ReaderAt, size int64) (*Course, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrInvalidBundle
+	}
+
+	files := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	readLimit := func(name string, limit int) ([]byte, error) {
+		f := files[name]
+		if f == nil {
+			return nil, nil
+		}
+
+		fr, err := f.Open()
+		if err != nil {
+			return nil, ErrInvalidBundle
+		}
+		defer fr.Close()
+
+		b, err := io.ReadAll(io.LimitReader(fr, int64(limit)+1))
+		if err != nil {
+			return nil, ErrInvalidBundle
+		}
+		if len(b) > limit {
+			return nil, ErrInvalidBundle
+		}
+		return b, nil
+	}
+
+	read := func(name string) ([]byte, error) {
+		return readLimit(name, maxFileSize)
+	}
+
+	readString := func(name string) (string, error) {
+		b, err := read(name)
+		return string(b), err
+	}
+
+	b, err := read("course.json")
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, ErrInvalidBundle
+	}
+
+	var x Course
+	err = json.Unmarshal(b, &x)
+	if err != nil {
+		return nil, ErrInvalidBundle
+	}
+	if x.Version < 1 || x.Version > Version {
+		return nil, ErrUnsupportedVersion
+	}
+
+	x.Desc, err = readString("course.md")
+	if err != nil {
+		return nil, err
+	}
+	x.EnrollDetail, err = readString("enroll.md")
+	if err != nil {
+		return nil, err
+	}
+	x.Image, err = read("cover.jpg")
+	if err != nil {
+		return nil, err
+	}
+
+	for i, s := range x.Sections {
+		if s == nil {
+			return nil, ErrInvalidBundle
+		}
+		s.Desc, err = readString(sectionFile(i))
+		if err != nil {
+			return nil, err
+		}
+
+		for j, c := range s.Contents {
+			if c == nil {
+				return nil, ErrInvalidBundle
+			}
+			c.Desc, err = readString(contentFile(i, j))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for i, a := range x.Assignments {
+		if a == nil {
+			return nil, ErrInvalidBundle
+		}
+		a.Desc, err = readString(assignmentFile(i))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for name := range files {
+		if !validFileName(name) {
+			continue
+		}
+		if x.Files == nil {
+			x.Files = make(map[string][]byte)
+		}
+		x.Files[name], err = readLimit(name, maxStoredFileSize)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &x, nil
+}
+
+var reFileName = regexp.MustCompile(`^files/[0-9a-z]+(\.[0-9a-z]+)?$`)
+
+// validFileName checks is name a stored file's name in bundle
+func validFileName(name string) bool {
+	return reFileName.MatchString(name)
+}
+
+var reFileRef = regexp.MustCompile(regexp.QuoteMeta(fileRef) + `files/[0-9a-z]+(\.[0-9a-z]+)?`)
+
+// fileName returns stored file's name in bundle from file reference
+func fileName(ref string) (string, bool) {
+	name := strings.TrimPrefix(ref, fileRef)
+	if len(name) == len(ref) || !validFileName(name) {
+		return "", false
+	}
+	return name, true
+}
@@ -0,0 +1,151 @@
+package bundle_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/acoshift/acourse/internal/pkg/bundle"
+)
+
+var _ = Describe("Bundle", func() {
+	x := &Course{
+		Title:        "Go",
+		ShortDesc:    "Learn Go",
+		Desc:         "# Go\n\nLearn Go in a week\n\n![gopher](bundle:files/001.png)",
+		EnrollDetail: "Transfer to **bank**",
+		Image:        []byte{0xff, 0xd8, 0xff},
+		Files: map[string][]byte{
+			"files/001.png": {0x89, 0x50, 0x4e, 0x47},
+			"files/002.pdf": []byte("%PDF"),
+		},
+		Start:    time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+		URL:      "go",
+		Category: "programming",
+		Tags:     []string{"go", "backend"},
+		Type:     1,
+		Price:    1000,
+		Discount: 500,
+		Option:   Option{Public: true, Discount: true},
+		Sections: []*Section{
+			{
+				Contents: []*Content{
+					{Title: "Intro", Desc: "intro"},
+				},
+			},
+			{
+				Title: "Basic",
+				Desc:  "basic section",
+				Contents: []*Content{
+					{
+						Title:       "Variables",
+						Desc:        "variables",
+						VideoID:     "abc",
+						VideoType:   1,
+						Release:     1,
+						ReleaseDays: 7,
+						Attachments: []*Attachment{
+							{Filename: "slide.pdf", Size: 10, DownloadURL: "bundle:files/002.pdf"},
+							{Filename: "link.pdf", Size: 10, DownloadURL: "https://example.com/link.pdf"},
+						},
+						Quiz: &Quiz{
+							Title:    "Variables",
+							PassMark: 50,
+							Questions: []*Question{
+								{Type: 1, Question: "1 + 1", Choices: []string{"1", "2"}, Corrects: []int64{1}},
+							},
+						},
+					},
+				},
+			},
+		},
+		Assignments: []*Assignment{
+			{Title: "Hello", Desc: "print hello", Open: true, DueDays: 7},
+		},
+	}
+
+	It("should read written bundle", func() {
+		var buf bytes.Buffer
+		Expect(Write(&buf, x)).To(Succeed())
+
+		r, err := Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r).To(Equal(x))
+	})
+
+	It("should keep descriptions as markdown files", func() {
+		var buf bytes.Buffer
+		Expect(Write(&buf, x)).To(Succeed())
+
+		zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		Expect(err).NotTo(HaveOccurred())
+
+		var names []string
+		for _, f := range zr.File {
+			names = append(names, f.Name)
+		}
+		Expect(names).To(ConsistOf(
+			"course.json",
+			"course.md",
+			"enroll.md",
+			"cover.jpg",
+			"sections/001/section.md",
+			"sections/001/001.md",
+			"sections/002/section.md",
+			"sections/002/001.md",
+			"assignments/001.md",
+			"files/001.png",
+			"files/002.pdf",
+		))
+	})
+
+	It("should error when stored file name is invalid", func() {
+		y := *x
+		y.Files = map[string][]byte{"../001.png": {0x89}}
+
+		var buf bytes.Buffer
+		Expect(Write(&buf, &y)).To(Equal(ErrInvalidBundle))
+	})
+
+	It("should read version 1 bundle", func() {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		w, _ := zw.Create("course.json")
+		w.Write([]byte(`{"version": 1, "title": "Go"}`))
+		Expect(zw.Close()).To(Succeed())
+
+		r, err := Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Title).To(Equal("Go"))
+		Expect(r.Files).To(BeEmpty())
+	})
+
+	It("should error when data is not zip", func() {
+		b := []byte("not zip")
+		_, err := Read(bytes.NewReader(b), int64(len(b)))
+		Expect(err).To(Equal(ErrInvalidBundle))
+	})
+
+	It("should error when course.json is missing", func() {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		Expect(zw.Close()).To(Succeed())
+
+		_, err := Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		Expect(err).To(Equal(ErrInvalidBundle))
+	})
+
+	It("should error when version is not supported", func() {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		w, _ := zw.Create("course.json")
+		w.Write([]byte(`{"version": 99}`))
+		Expect(zw.Close()).To(Succeed())
+
+		_, err := Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		Expect(err).To(Equal(ErrUnsupportedVersion))
+	})
+})
@@ -0,0 +1,12 @@
+package bundle
+
+import (
+	"errors"
+)
+
+var (
+	ErrInvalidBundle      = errors.New("bundle: invalid bundle")
+	ErrUnsupportedVersion = errors.New("bundle: unsupported version")
+
+	errFileTooLarge = errors.New("bundle: file too large")
+)
@@ -0,0 +1,266 @@
+package bundle
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/acoshift/acourse/internal/pkg/course"
+	"github.com/acoshift/acourse/internal/pkg/file"
+	"github.com/acoshift/acourse/internal/pkg/quiz"
+	"github.com/acoshift/acourse/internal/pkg/video"
+)
+
+// Export writes course as zip bundle into w,
+// stored files used by the course are downloaded into the bundle (see Course)
+func Export(ctx context.Context, w io.Writer, courseID string) error {
+	c, err := course.Get(ctx, courseID)
+	if err != nil {
+		return err
+	}
+
+	x := Course{
+		Title:     c.Title,
+		ShortDesc: c.ShortDesc,
+		Start:     c.Start,
+		URL:       c.URL,
+		Category:  c.Category.Slug,
+		Tags:      c.Tags,
+		Type:      c.Type,
+		Price:     c.Price,
+		Discount:  c.Discount,
+		Option:    Option(c.Option),
+	}
+	e := exporter{Course: &x}
+
+	x.Desc, err = e.markdown(ctx, c.Desc)
+	if err != nil {
+		return err
+	}
+	x.EnrollDetail, err = e.markdown(ctx, c.EnrollDetail)
+	if err != nil {
+		return err
+	}
+
+	if c.Image != "" {
+		x.Image, err = download(ctx, c.Image, maxFileSize)
+		if err != nil {
+			return err
+		}
+	}
+
+	sections, err := course.GetSections(ctx, courseID)
+	if err != nil {
+		return err
+	}
+	for _, s := range sections {
+		p := Section{
+			Title: s.Title,
+		}
+		p.Desc, err = e.markdown(ctx, s.Desc)
+		if err != nil {
+			return err
+		}
+		for _, c := range s.Contents {
+			q, err := e.content(ctx, c)
+			if err != nil {
+				return err
+			}
+			p.Contents = append(p.Contents, q)
+		}
+		x.Sections = append(x.Sections, &p)
+	}
+
+	assignments, err := course.GetAssignments(ctx, courseID)
+	if err != nil {
+		return err
+	}
+	for _, a := range assignments {
+		desc, err := e.markdown(ctx, a.Desc)
+		if err != nil {
+			return err
+		}
+		x.Assignments = append(x.Assignments, &Assignment{
+			Title:    a.Title,
+			Desc:     desc,
+			Open:     a.Open,
+			Optional: !a.Required,
+			DueAt:    a.DueAt,
+			DueDays:  a.DueDays,
+			LateDays: a.LateDays,
+		})
+	}
+
+	return Write(w, &x)
+}
+
+// exporter downloads stored files into course's bundle
+type exporter struct {
+	*Course
+
+	refs map[string]string // stored file's url to file reference
+}
+
+// file downloads stored file at url into bundle and returns its file reference,
+// url which is not stored file or too large file is returned as is
+func (e *exporter) file(ctx context.Context, url string) (string, error) {
+	if !file.IsStoreURL(url) {
+		return url, nil
+	}
+	if ref, ok := e.refs[url]; ok {
+		return ref, nil
+	}
+
+	b, err := download(ctx, url, maxStoredFileSize)
+	if err == errFileTooLarge {
+		return url, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if e.Files == nil {
+		e.Files = make(map[string][]byte)
+		e.refs = make(map[string]string)
+	}
+	name := fmt.Sprintf("%s%03d%s", filesDir, len(e.Files)+1, fileExt(url))
+	e.Files[name] = b
+	e.refs[url] = fileRef + name
+	return e.refs[url], nil
+}
+
+// markdown downloads stored files which are referenced in markdown into bundle,
+// and replaces their urls with file references
+func (e *exporter) markdown(ctx context.Context, s string) (string, error) {
+	urls := file.FindStoreURLs(s)
+	if len(urls) == 0 {
+		return s, nil
+	}
+
+	// replace longer urls first, so url which is prefix of another url does not break it
+	sort.Slice(urls, func(i, j int) bool { return len(urls[i]) > len(urls[j]) })
+
+	var oldnew []string
+	for _, url := range urls {
+		ref, err := e.file(ctx, url)
+		if err != nil {
+			return "", err
+		}
+		oldnew = append(oldnew, url, ref)
+	}
+	return strings.NewReplacer(oldnew...).Replace(s), nil
+}
+
+func (e *exporter) content(ctx context.Context, c *course.Content) (*Content, error) {
+	x := Content{
+		Title:     c.Title,
+		VideoID:   c.VideoID,
+		VideoType: c.VideoType,
+
+		Release:       c.Release,
+		ReleaseDays:   c.ReleaseDays,
+		ReleaseNotify: c.ReleaseNotify,
+	}
+
+	var err error
+	x.Desc, err = e.markdown(ctx, c.Desc)
+	if err != nil {
+		return nil, err
+	}
+
+	// contents created before video providers have no video type
+	if x.VideoType == 0 && x.VideoID != "" {
+		x.VideoType = video.Youtube
+	}
+
+	if x.VideoType == video.File {
+		x.VideoID, err = e.file(ctx, x.VideoID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	attachments, err := course.GetAttachments(ctx, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range attachments {
+		downloadURL, err := e.file(ctx, a.DownloadURL)
+		if err != nil {
+			return nil, err
+		}
+		x.Attachments = append(x.Attachments, &Attachment{
+			Filename:    a.Filename,
+			Size:        a.Size,
+			DownloadURL: downloadURL,
+		})
+	}
+
+	qz, err := quiz.Get(ctx, c.ID)
+	if err == quiz.ErrNotFound {
+		return &x, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	x.Quiz = &Quiz{
+		Title:       qz.Title,
+		PassMark:    qz.PassMark,
+		MaxAttempts: qz.MaxAttempts,
+	}
+	for _, q := range qz.Questions {
+		x.Quiz.Questions = append(x.Quiz.Questions, &Question{
+			Type:     q.Type,
+			Question: q.Question,
+			Choices:  q.Choices,
+			Corrects: q.Corrects,
+			Answers:  q.Answers,
+			Match:    q.Match,
+		})
+	}
+
+	return &x, nil
+}
+
+// fileExt returns lower case extension of url's file which can be used in bundle's file name
+func fileExt(url string) string {
+	ext := strings.ToLower(path.Ext(url))
+	if !validFileName("files/0" + ext) {
+		return ""
+	}
+	return ext
+}
+
+func download(ctx context.Context, url string, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("bundle: download %s: %s", url, resp.Status)
+	}
+	if resp.ContentLength > limit {
+		return nil, errFileTooLarge
+	}
+
+	b, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > limit {
+		return nil, errFileTooLarge
+	}
+	return b, nil
+}
@@ -0,0 +1,385 @@
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/acoshift/pgsql/pgctx"
+
+	"github.com/acoshift/acourse/internal/pkg/course"
+	"github.com/acoshift/acourse/internal/pkg/file"
+	"github.com/acoshift/acourse/internal/pkg/quiz"
+	"github.com/acoshift/acourse/internal/pkg/video"
+)
+
+// Result is import result
+type Result struct {
+	CourseID string
+
+	// Conflicts are parts of the bundle which can not be imported as is
+	Conflicts []string
+}
+
+// Import creates new course owned by ownerID from x,
+// the course is imported as private course which does not open for enroll,
+// stored files in the bundle are uploaded into the storage
+func Import(ctx context.Context, x *Course, ownerID string) (*Result, error) {
+	var r Result
+	err := pgctx.RunInTx(ctx, func(ctx context.Context) error {
+		r = Result{}
+		im := importer{Course: x, Result: &r}
+
+		desc, err := im.markdown(ctx, x.Desc)
+		if err != nil {
+			return err
+		}
+		enrollDetail, err := im.markdown(ctx, x.EnrollDetail)
+		if err != nil {
+			return err
+		}
+
+		id, err := course.Create(ctx, &course.CreateArgs{
+			UserID:    ownerID,
+			Title:     x.Title,
+			ShortDesc: x.ShortDesc,
+			LongDesc:  desc,
+			Start:     x.Start,
+		})
+		if err != nil {
+			return err
+		}
+		r.CourseID = id
+
+		if len(x.Image) > 0 {
+			err = course.SetCoverImage(ctx, id, bytes.NewReader(x.Image))
+			if err != nil {
+				return err
+			}
+		}
+
+		typ := x.Type
+		if typ != course.Live && typ != course.Video && typ != course.EBook {
+			r.Conflicts = append(r.Conflicts, fmt.Sprintf("unknown course type %d, imported as video", typ))
+			typ = course.Video
+		}
+
+		err = course.Update(ctx, &course.UpdateArgs{
+			ID:           id,
+			Title:        x.Title,
+			ShortDesc:    x.ShortDesc,
+			LongDesc:     desc,
+			Start:        x.Start,
+			Type:         typ,
+			Price:        x.Price,
+			Discount:     x.Discount,
+			UseDiscount:  x.Option.Discount,
+			EnrollDetail: enrollDetail,
+		})
+		if err != nil {
+			return err
+		}
+
+		// imported course is private and not open for enroll until owner reviews it
+		err = course.SetOption(ctx, id, course.Option{
+			Attend:     x.Option.Attend,
+			Assignment: x.Option.Assignment,
+			Discount:   x.Option.Discount,
+		})
+		if err != nil {
+			return err
+		}
+
+		if x.URL != "" {
+			ok, err := urlAvailable(ctx, x.URL)
+			if err != nil {
+				return err
+			}
+			if ok {
+				err = course.SetURL(ctx, id, x.URL)
+				if err != nil {
+					return err
+				}
+			} else {
+				r.Conflicts = append(r.Conflicts, fmt.Sprintf("url %q is not available", x.URL))
+			}
+		}
+
+		if x.Category != "" {
+			categoryID, err := course.GetCategoryIDBySlug(ctx, x.Category)
+			if err == course.ErrNotFound {
+				r.Conflicts = append(r.Conflicts, fmt.Sprintf("category %q not found", x.Category))
+			} else if err != nil {
+				return err
+			} else {
+				err = course.SetCategory(ctx, id, categoryID)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		err = course.SetTags(ctx, id, x.Tags)
+		if err == course.ErrInvalidTags {
+			r.Conflicts = append(r.Conflicts, "invalid tags, tags are not imported")
+		} else if err != nil {
+			return err
+		}
+
+		for _, s := range x.Sections {
+			err = im.section(ctx, id, s)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, a := range x.Assignments {
+			desc, err := im.markdown(ctx, a.Desc)
+			if err != nil {
+				return err
+			}
+
+			assignmentID, err := course.CreateAssignment(ctx, &course.CreateAssignmentArgs{
+				CourseID: id,
+				Title:    a.Title,
+				Desc:     desc,
+				Required: !a.Optional,
+				DueAt:    a.DueAt,
+				DueDays:  a.DueDays,
+				LateDays: a.LateDays,
+			})
+			if err != nil {
+				return fmt.Errorf("assignment %q: %v", a.Title, err)
+			}
+
+			if a.Open {
+				err = course.SetAssignmentOpen(ctx, id, assignmentID, true)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// urlAvailable checks is url can be set to new course without error,
+// unique violation will abort the transaction so url must be checked before set
+func urlAvailable(ctx context.Context, url string) (bool, error) {
+	if !course.ValidURL(url) {
+		return false, nil
+	}
+
+	_, err := course.GetIDByURL(ctx, url)
+	if err == nil {
+		return false, nil
+	}
+	if err != course.ErrNotFound {
+		return false, err
+	}
+
+	_, err = course.GetIDByOldURL(ctx, url)
+	if err == nil {
+		return false, nil
+	}
+	if err != course.ErrNotFound {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// importer uploads stored files from course's bundle
+type importer struct {
+	*Course
+	*Result
+
+	urls map[string]string // file reference to uploaded file's url
+}
+
+// file uploads stored file of file reference and returns its url,
+// ref which is not file reference is returned as is
+func (im *importer) file(ctx context.Context, ref string) (string, error) {
+	name, ok := fileName(ref)
+	if !ok {
+		return ref, nil
+	}
+	if url, ok := im.urls[ref]; ok {
+		return url, nil
+	}
+
+	b, ok := im.Files[name]
+	if !ok {
+		im.Conflicts = append(im.Conflicts, fmt.Sprintf("file %q not found in bundle", name))
+		return "", nil
+	}
+
+	url, err := file.Store(ctx, bytes.NewReader(b), file.GenerateFilename()+path.Ext(name), false)
+	if err != nil {
+		return "", err
+	}
+
+	if im.urls == nil {
+		im.urls = make(map[string]string)
+	}
+	im.urls[ref] = url
+	return url, nil
+}
+
+// markdown uploads stored files which are referenced in markdown,
+// and replaces file references with their urls
+func (im *importer) markdown(ctx context.Context, s string) (string, error) {
+	urls := make(map[string]string)
+	for _, ref := range reFileRef.FindAllString(s, -1) {
+		url, err := im.file(ctx, ref)
+		if err != nil {
+			return "", err
+		}
+		urls[ref] = url
+	}
+	if len(urls) == 0 {
+		return s, nil
+	}
+
+	return reFileRef.ReplaceAllStringFunc(s, func(ref string) string {
+		if url := urls[ref]; url != "" {
+			return url
+		}
+		return ref
+	}), nil
+}
+
+// attachment uploads attachment's file if it is kept in bundle and returns its download url
+func (im *importer) attachment(ctx context.Context, a *Attachment) (string, error) {
+	name, ok := fileName(a.DownloadURL)
+	if !ok {
+		return a.DownloadURL, nil
+	}
+
+	b, ok := im.Files[name]
+	if !ok {
+		im.Conflicts = append(im.Conflicts, fmt.Sprintf("file %q not found in bundle", name))
+		return "", nil
+	}
+
+	return file.StoreAttachment(ctx, bytes.NewReader(b), file.GenerateFilename()+path.Ext(name), a.Filename)
+}
+
+func (im *importer) section(ctx context.Context, courseID string, s *Section) error {
+	var sectionID string
+	if s.Title != "" {
+		desc, err := im.markdown(ctx, s.Desc)
+		if err != nil {
+			return err
+		}
+
+		sectionID, err = course.CreateSection(ctx, &course.CreateSectionArgs{
+			CourseID: courseID,
+			Title:    s.Title,
+			Desc:     desc,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, c := range s.Contents {
+		desc, err := im.markdown(ctx, c.Desc)
+		if err != nil {
+			return err
+		}
+
+		videoID := c.VideoID
+		if c.VideoType == video.File {
+			videoID, err = im.file(ctx, videoID)
+			if err != nil {
+				return err
+			}
+		}
+		if videoID != "" {
+			// video from other source, e.g. file in other storage, can not be embedded by this site
+			_, err = video.Parse(c.VideoType, videoID)
+			if err != nil {
+				im.Conflicts = append(im.Conflicts, fmt.Sprintf("content %q: video %q is not available, imported without video", c.Title, videoID))
+				videoID = ""
+			}
+		}
+
+		contentID, err := course.CreateContent(ctx, &course.CreateContentArgs{
+			ID:        courseID,
+			SectionID: sectionID,
+			Title:     c.Title,
+			LongDesc:  desc,
+			VideoID:   videoID,
+			VideoType: c.VideoType,
+
+			Release:       c.Release,
+			ReleaseDays:   c.ReleaseDays,
+			ReleaseNotify: c.ReleaseNotify,
+		})
+		if err != nil {
+			return fmt.Errorf("content %q: %v", c.Title, err)
+		}
+
+		for _, a := range c.Attachments {
+			if a == nil {
+				continue
+			}
+
+			downloadURL, err := im.attachment(ctx, a)
+			if err != nil {
+				return fmt.Errorf("content %q: attachment %q: %v", c.Title, a.Filename, err)
+			}
+			if downloadURL == "" {
+				continue
+			}
+
+			_, err = course.LinkAttachment(ctx, contentID, a.Filename, a.Size, downloadURL)
+			if err != nil {
+				return fmt.Errorf("content %q: attachment %q: %v", c.Title, a.Filename, err)
+			}
+		}
+
+		if c.Quiz == nil {
+			continue
+		}
+
+		quizID, err := quiz.Save(ctx, &quiz.SaveArgs{
+			ContentID:   contentID,
+			Title:       c.Quiz.Title,
+			PassMark:    c.Quiz.PassMark,
+			MaxAttempts: c.Quiz.MaxAttempts,
+		})
+		if err != nil {
+			return fmt.Errorf("content %q: quiz: %v", c.Title, err)
+		}
+
+		for _, q := range c.Quiz.Questions {
+			if q == nil {
+				continue
+			}
+
+			_, err = quiz.AddQuestion(ctx, &quiz.Question{
+				QuizID:   quizID,
+				Type:     q.Type,
+				Question: q.Question,
+				Choices:  q.Choices,
+				Corrects: q.Corrects,
+				Answers:  q.Answers,
+				Match:    q.Match,
+			})
+			if err != nil {
+				return fmt.Errorf("content %q: quiz: %v", c.Title, err)
+			}
+		}
+	}
+
+	return nil
+}
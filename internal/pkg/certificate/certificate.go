@@ -0,0 +1,126 @@
+package certificate
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"time"
+
+	"github.com/acoshift/pgsql/pgctx"
+
+	"github.com/acoshift/acourse/internal/pkg/course"
+)
+
+// Certificate of course completion
+type Certificate struct {
+	Code      string
+	CreatedAt time.Time
+	User      struct {
+		ID       string
+		Username string
+		Name     string
+	}
+	Course struct {
+		ID    string
+		Title string
+		URL   string
+		Owner struct {
+			Name string
+		}
+	}
+}
+
+// CourseLink returns course link
+func (x *Certificate) CourseLink() string {
+	if x.Course.URL == "" {
+		return x.Course.ID
+	}
+	return x.Course.URL
+}
+
+// UserName returns certificate owner's name
+func (x *Certificate) UserName() string {
+	if x.User.Name == "" {
+		return x.User.Username
+	}
+	return x.User.Name
+}
+
+// Issue issues a certificate to user who completed a course,
+// returns the existing certificate's code if user already has one
+func Issue(ctx context.Context, userID, courseID string) (string, error) {
+	completed, err := course.IsCompleted(ctx, userID, courseID)
+	if err != nil {
+		return "", err
+	}
+	if !completed {
+		return "", ErrNotCompleted
+	}
+
+	code, err := generateCode()
+	if err != nil {
+		return "", err
+	}
+
+	// language=SQL
+	_, err = pgctx.Exec(ctx, `
+		insert into certificates
+			(code, user_id, course_id)
+		values
+			($1, $2, $3)
+		on conflict (user_id, course_id) do nothing
+	`, code, userID, courseID)
+	if err != nil {
+		return "", err
+	}
+
+	// language=SQL
+	err = pgctx.QueryRow(ctx, `
+		select code
+		from certificates
+		where user_id = $1 and course_id = $2
+	`, userID, courseID).Scan(&code)
+	return code, err
+}
+
+// Get gets a certificate from verification code
+func Get(ctx context.Context, code string) (*Certificate, error) {
+	var x Certificate
+	// language=SQL
+	err := pgctx.QueryRow(ctx, `
+		select
+			x.code, x.created_at,
+			u.id, u.username, u.name,
+			c.id, c.title, coalesce(c.url, ''),
+			o.name
+		from certificates x
+			left join users u on x.user_id = u.id
+			left join courses c on x.course_id = c.id
+			left join users o on c.user_id = o.id
+		where x.code = $1
+	`, code).Scan(
+		&x.Code, &x.CreatedAt,
+		&x.User.ID, &x.User.Username, &x.User.Name,
+		&x.Course.ID, &x.Course.Title, &x.Course.URL,
+		&x.Course.Owner.Name,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &x, nil
+}
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func generateCode() (string, error) {
+	b := make([]byte, 10)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(b), nil
+}
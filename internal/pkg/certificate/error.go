@@ -0,0 +1,11 @@
+package certificate
+
+import (
+	"errors"
+)
+
+var (
+	ErrNotFound     = errors.New("certificate: not found")
+	ErrNotCompleted = errors.New("certificate: course not completed")
+	ErrNoFont       = errors.New("certificate: font not configured")
+)
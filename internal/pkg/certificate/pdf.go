@@ -0,0 +1,78 @@
+package certificate
+
+import (
+	"io"
+	"log"
+
+	"github.com/jung-kurt/gofpdf"
+
+	"github.com/acoshift/acourse/internal/pkg/config"
+)
+
+var fontRegular, fontBold []byte
+
+// Init loads regular and bold ttf fonts for certificate from
+// "certificate_font" and "certificate_font_bold" config,
+// the fonts must contain Thai glyphs (e.g. Sarabun) since user and course names are mostly in Thai
+func Init() {
+	fontRegular = config.Bytes("certificate_font")
+	fontBold = config.Bytes("certificate_font_bold")
+	if !CanRender() {
+		log.Println("certificate: font not configured, certificate pdf is disabled")
+	}
+}
+
+// CanRender returns true if certificate fonts are configured
+func CanRender() bool {
+	return len(fontRegular) > 0 && len(fontBold) > 0
+}
+
+// Render renders certificate as pdf
+func Render(w io.Writer, x *Certificate, verifyURL string) error {
+	if !CanRender() {
+		return ErrNoFont
+	}
+
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	pdf.AddUTF8FontFromBytes("cert", "", fontRegular)
+	pdf.AddUTF8FontFromBytes("cert", "B", fontBold)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.AddPage()
+
+	width, height := pdf.GetPageSize()
+
+	// border
+	pdf.SetDrawColor(30, 90, 160)
+	pdf.SetLineWidth(1.5)
+	pdf.Rect(10, 10, width-20, height-20, "D")
+	pdf.SetLineWidth(0.3)
+	pdf.Rect(14, 14, width-28, height-28, "D")
+
+	line := func(y float64, style string, size float64, s string) {
+		pdf.SetFont("cert", style, size)
+		pdf.SetXY(20, y)
+		pdf.CellFormat(width-40, size/2, s, "", 0, "C", false, 0, "")
+	}
+
+	pdf.SetTextColor(30, 90, 160)
+	line(40, "B", 36, "Certificate of Completion")
+
+	pdf.SetTextColor(60, 60, 60)
+	line(70, "", 16, "This is to certify that")
+	pdf.SetTextColor(0, 0, 0)
+	line(85, "B", 30, x.UserName())
+	pdf.SetTextColor(60, 60, 60)
+	line(110, "", 16, "has successfully completed the course")
+	pdf.SetTextColor(0, 0, 0)
+	line(125, "B", 24, x.Course.Title)
+
+	pdf.SetTextColor(60, 60, 60)
+	line(150, "", 14, "Instructor: "+x.Course.Owner.Name)
+	line(160, "", 14, "Issued on "+x.CreatedAt.In(config.Location()).Format("2 January 2006"))
+
+	pdf.SetTextColor(120, 120, 120)
+	line(180, "", 10, "Verification code: "+x.Code)
+	line(186, "", 10, verifyURL)
+
+	return pdf.Output(w)
+}
@@ -0,0 +1,175 @@
+package course
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/acoshift/pgsql/pgctx"
+)
+
+// Archive archives a course,
+// archived course will be hidden from public list and closed for enroll,
+// enrolled users still can learn but can not submit anything
+func Archive(ctx context.Context, courseID string) error {
+	// language=SQL
+	_, err := pgctx.Exec(ctx, `
+		update courses
+		set
+			archived_at = now(),
+			updated_at = now()
+		where id = $1 and archived_at is null
+	`, courseID)
+	return err
+}
+
+// Unarchive restores archived course
+func Unarchive(ctx context.Context, courseID string) error {
+	// language=SQL
+	_, err := pgctx.Exec(ctx, `
+		update courses
+		set
+			archived_at = null,
+			updated_at = now()
+		where id = $1
+	`, courseID)
+	return err
+}
+
+// Delete deletes a course and all its data,
+// course which has enrolls or payments can not be deleted
+func Delete(ctx context.Context, courseID string) error {
+	return pgctx.RunInTx(ctx, func(ctx context.Context) error {
+		// language=SQL
+		err := pgctx.QueryRow(ctx, `
+			select 1
+			from courses
+			where id = $1
+			for update
+		`, courseID).Scan(new(int))
+		if err == sql.ErrNoRows {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		var used bool
+		// language=SQL
+		err = pgctx.QueryRow(ctx, `
+			select
+				exists (select 1 from enrolls where course_id = $1) or
+				exists (select 1 from payments where course_id = $1)
+		`, courseID).Scan(&used)
+		if err != nil {
+			return err
+		}
+		if used {
+			return ErrHasEnrolls
+		}
+
+		// language=SQL
+		_, err = pgctx.Exec(ctx, `
+			delete from quiz_attempts
+			where quiz_id in (
+				select q.id
+				from quizzes q
+					left join course_contents c on q.content_id = c.id
+				where c.course_id = $1
+			)
+		`, courseID)
+		if err != nil {
+			return err
+		}
+
+		// language=SQL
+		_, err = pgctx.Exec(ctx, `
+			delete from quiz_questions
+			where quiz_id in (
+				select q.id
+				from quizzes q
+					left join course_contents c on q.content_id = c.id
+				where c.course_id = $1
+			)
+		`, courseID)
+		if err != nil {
+			return err
+		}
+
+		// language=SQL
+		_, err = pgctx.Exec(ctx, `
+			delete from quizzes
+			where content_id in (select id from course_contents where course_id = $1)
+		`, courseID)
+		if err != nil {
+			return err
+		}
+
+		// language=SQL
+		_, err = pgctx.Exec(ctx, `
+			delete from course_content_attachments
+			where content_id in (select id from course_contents where course_id = $1)
+		`, courseID)
+		if err != nil {
+			return err
+		}
+
+		// language=SQL
+		_, err = pgctx.Exec(ctx, `
+			delete from content_comments
+			where content_id in (select id from course_contents where course_id = $1)
+		`, courseID)
+		if err != nil {
+			return err
+		}
+
+		// language=SQL
+		_, err = pgctx.Exec(ctx, `
+			delete from content_release_notifies
+			where content_id in (select id from course_contents where course_id = $1)
+		`, courseID)
+		if err != nil {
+			return err
+		}
+
+		// language=SQL
+		_, err = pgctx.Exec(ctx, `
+			delete from user_assignments
+			where assignment_id in (select id from assignments where course_id = $1)
+		`, courseID)
+		if err != nil {
+			return err
+		}
+
+		// language=SQL
+		_, err = pgctx.Exec(ctx, `
+			delete from course_prerequisites
+			where course_id = $1 or prerequisite_id = $1
+		`, courseID)
+		if err != nil {
+			return err
+		}
+
+		for _, table := range []string{
+			"assignments",
+			"progresses",
+			"certificates",
+			"attends",
+			"course_contents",
+			"course_sections",
+			"course_url_history",
+			"course_tags",
+			"course_members",
+			"reviews",
+			"course_options",
+		} {
+			_, err = pgctx.Exec(ctx, `delete from `+table+` where course_id = $1`, courseID)
+			if err != nil {
+				return err
+			}
+		}
+
+		// language=SQL
+		_, err = pgctx.Exec(ctx, `delete from courses where id = $1`, courseID)
+		return err
+	})
+}
@@ -2,22 +2,230 @@ package course
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
+	"time"
 
+	"github.com/acoshift/pgsql"
 	"github.com/acoshift/pgsql/pgctx"
 )
 
 // Assignment model
 type Assignment struct {
-	ID    string
-	Title string
-	Desc  string
-	Open  bool
+	ID       string
+	CourseID string
+	Title    string
+	Desc     string
+	Open     bool
+	Required bool      // required assignment must be passed to complete the course
+	DueAt    time.Time // absolute due date
+	DueDays  int       // due date relative to user's enroll date, used when DueAt is zero
+	LateDays int       // late window after due date, submission inside the window is marked as late
+}
+
+// Deadline returns assignment's due date for user who enrolled at enrolledAt,
+// zero time means assignment has no due date
+func (x *Assignment) Deadline(enrolledAt time.Time) time.Time {
+	if !x.DueAt.IsZero() {
+		return x.DueAt
+	}
+	if x.DueDays > 0 && !enrolledAt.IsZero() {
+		return enrolledAt.AddDate(0, 0, x.DueDays)
+	}
+	return time.Time{}
+}
+
+// CloseAt returns the time when assignment will stop accepting submissions
+// for user who enrolled at enrolledAt, zero time means assignment never closes
+func (x *Assignment) CloseAt(enrolledAt time.Time) time.Time {
+	t := x.Deadline(enrolledAt)
+	if t.IsZero() {
+		return t
+	}
+	return t.AddDate(0, 0, x.LateDays)
+}
+
+// Accepting returns true if user who enrolled at enrolledAt can submit the assignment
+func (x *Assignment) Accepting(enrolledAt time.Time) bool {
+	if !x.Open {
+		return false
+	}
+	t := x.CloseAt(enrolledAt)
+	return t.IsZero() || time.Now().Before(t)
+}
+
+// IsLate returns true if user who enrolled at enrolledAt submits after the due date
+func (x *Assignment) IsLate(enrolledAt time.Time) bool {
+	t := x.Deadline(enrolledAt)
+	return !t.IsZero() && time.Now().After(t)
+}
+
+type CreateAssignmentArgs struct {
+	CourseID string
+	Title    string
+	Desc     string
+	Required bool
+	DueAt    time.Time
+	DueDays  int
+	LateDays int
+}
+
+// CreateAssignment creates new course assignment, the assignment is closed by default
+func CreateAssignment(ctx context.Context, m *CreateAssignmentArgs) (string, error) {
+	if m.Title == "" {
+		return "", fmt.Errorf("title required")
+	}
+
+	// due_at is timestamp without time zone, time is stored in UTC
+	dueAt := m.DueAt.UTC()
+
+	var id string
+	// language=SQL
+	err := pgctx.QueryRow(ctx, `
+		insert into assignments
+			(
+				course_id,
+				i,
+				title, long_desc, required,
+				due_at, due_days, late_days
+			)
+		values
+			(
+				$1,
+				(select coalesce(max(i)+1, 0) from assignments where course_id = $1),
+				$2, $3, $4,
+				$5, $6, $7
+			)
+		returning id
+	`, m.CourseID, m.Title, m.Desc, m.Required, pgsql.NullTime(&dueAt), m.DueDays, m.LateDays).Scan(&id)
+	return id, err
+}
+
+type UpdateAssignmentArgs struct {
+	CourseID     string
+	AssignmentID string
+	Title        string
+	Desc         string
+	Required     bool
+	DueAt        time.Time
+	DueDays      int
+	LateDays     int
+}
+
+// UpdateAssignment updates a course assignment
+func UpdateAssignment(ctx context.Context, m *UpdateAssignmentArgs) error {
+	if m.Title == "" {
+		return fmt.Errorf("title required")
+	}
+
+	// due_at is timestamp without time zone, time is stored in UTC
+	dueAt := m.DueAt.UTC()
+
+	// language=SQL
+	res, err := pgctx.Exec(ctx, `
+		update assignments
+		set
+			title = $3,
+			long_desc = $4,
+			required = $5,
+			due_at = $6,
+			due_days = $7,
+			late_days = $8,
+			updated_at = now()
+		where id = $1 and course_id = $2
+	`, m.AssignmentID, m.CourseID, m.Title, m.Desc, m.Required, pgsql.NullTime(&dueAt), m.DueDays, m.LateDays)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// SetAssignmentOpen opens or closes a course assignment
+func SetAssignmentOpen(ctx context.Context, courseID, assignmentID string, open bool) error {
+	// language=SQL
+	res, err := pgctx.Exec(ctx, `
+		update assignments
+		set
+			open = $3,
+			updated_at = now()
+		where id = $1 and course_id = $2
+	`, assignmentID, courseID, open)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteAssignment deletes a course assignment,
+// an assignment which already has submissions can not be deleted
+func DeleteAssignment(ctx context.Context, courseID, assignmentID string) error {
+	return pgctx.RunInTx(ctx, func(ctx context.Context) error {
+		var submitted bool
+		// language=SQL
+		err := pgctx.QueryRow(ctx, `
+			select exists (
+				select 1
+				from user_assignments
+				where assignment_id = $1
+			)
+		`, assignmentID).Scan(&submitted)
+		if err != nil {
+			return err
+		}
+		if submitted {
+			return ErrAssignmentHasSubmissions
+		}
+
+		// language=SQL
+		_, err = pgctx.Exec(ctx, `
+			delete from assignments
+			where id = $1 and course_id = $2
+		`, assignmentID, courseID)
+		return err
+	})
+}
+
+// ReorderAssignments sets the order of course's assignments to match assignmentIDs,
+// assignmentIDs must contain every assignment of the course exactly once
+func ReorderAssignments(ctx context.Context, courseID string, assignmentIDs []string) error {
+	return reorder(ctx, "assignments", courseID, assignmentIDs, ErrInvalidAssignments)
+}
+
+// GetAssignment gets a course's assignment
+func GetAssignment(ctx context.Context, courseID, assignmentID string) (*Assignment, error) {
+	var x Assignment
+	// language=SQL
+	err := pgctx.QueryRow(ctx, `
+		select
+			id, course_id, title, long_desc, open, required,
+			due_at, due_days, late_days
+		from assignments
+		where id = $1 and course_id = $2
+	`, assignmentID, courseID).Scan(
+		&x.ID, &x.CourseID, &x.Title, &x.Desc, &x.Open, &x.Required,
+		pgsql.NullTime(&x.DueAt), &x.DueDays, &x.LateDays,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &x, nil
 }
 
 func GetAssignments(ctx context.Context, courseID string) ([]*Assignment, error) {
 	// language=SQL
 	rows, err := pgctx.Query(ctx, `
-		select id, title, long_desc, open
+		select
+			id, course_id, title, long_desc, open, required,
+			due_at, due_days, late_days
 		from assignments
 		where course_id = $1
 		order by i asc
@@ -30,7 +238,10 @@ func GetAssignments(ctx context.Context, courseID string) ([]*Assignment, error)
 	var xs []*Assignment
 	for rows.Next() {
 		var x Assignment
-		err = rows.Scan(&x.ID, &x.Title, &x.Desc, &x.Open)
+		err = rows.Scan(
+			&x.ID, &x.CourseID, &x.Title, &x.Desc, &x.Open, &x.Required,
+			pgsql.NullTime(&x.DueAt), &x.DueDays, &x.LateDays,
+		)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,111 @@
+package course_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/acoshift/acourse/internal/pkg/course"
+)
+
+var _ = Describe("Assignment", func() {
+	enrolledAt := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	dueAt := time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	Describe("Deadline", func() {
+		It("should be zero when assignment has no due date", func() {
+			x := Assignment{}
+
+			Expect(x.Deadline(enrolledAt)).To(BeZero())
+		})
+
+		It("should return due date", func() {
+			x := Assignment{DueAt: dueAt}
+
+			Expect(x.Deadline(enrolledAt)).To(Equal(dueAt))
+		})
+
+		It("should return due date relative to enroll date", func() {
+			x := Assignment{DueDays: 7}
+
+			Expect(x.Deadline(enrolledAt)).To(Equal(enrolledAt.AddDate(0, 0, 7)))
+		})
+
+		It("should prefer due date over due days", func() {
+			x := Assignment{DueAt: dueAt, DueDays: 7}
+
+			Expect(x.Deadline(enrolledAt)).To(Equal(dueAt))
+		})
+
+		It("should be zero when due days is used but user not enrolled", func() {
+			x := Assignment{DueDays: 7}
+
+			Expect(x.Deadline(time.Time{})).To(BeZero())
+		})
+	})
+
+	Describe("CloseAt", func() {
+		It("should be zero when assignment has no due date", func() {
+			x := Assignment{LateDays: 3}
+
+			Expect(x.CloseAt(enrolledAt)).To(BeZero())
+		})
+
+		It("should return due date when assignment has no late window", func() {
+			x := Assignment{DueAt: dueAt}
+
+			Expect(x.CloseAt(enrolledAt)).To(Equal(dueAt))
+		})
+
+		It("should add late window to due date", func() {
+			x := Assignment{DueAt: dueAt, LateDays: 3}
+
+			Expect(x.CloseAt(enrolledAt)).To(Equal(dueAt.AddDate(0, 0, 3)))
+		})
+
+		It("should add late window to relative due date", func() {
+			x := Assignment{DueDays: 7, LateDays: 3}
+
+			Expect(x.CloseAt(enrolledAt)).To(Equal(enrolledAt.AddDate(0, 0, 10)))
+		})
+	})
+
+	Describe("Accepting", func() {
+		It("should not accept when assignment is closed", func() {
+			x := Assignment{}
+
+			Expect(x.Accepting(enrolledAt)).To(BeFalse())
+		})
+
+		It("should accept when assignment has no due date", func() {
+			x := Assignment{Open: true}
+
+			Expect(x.Accepting(enrolledAt)).To(BeTrue())
+		})
+
+		It("should accept before due date", func() {
+			x := Assignment{Open: true, DueAt: time.Now().Add(time.Hour)}
+
+			Expect(x.Accepting(enrolledAt)).To(BeTrue())
+		})
+
+		It("should accept inside late window", func() {
+			x := Assignment{Open: true, DueAt: time.Now().Add(-time.Hour), LateDays: 1}
+
+			Expect(x.Accepting(enrolledAt)).To(BeTrue())
+		})
+
+		It("should not accept after late window", func() {
+			x := Assignment{Open: true, DueAt: time.Now().AddDate(0, 0, -2), LateDays: 1}
+
+			Expect(x.Accepting(enrolledAt)).To(BeFalse())
+		})
+
+		It("should not accept after relative due date", func() {
+			x := Assignment{Open: true, DueDays: 7}
+
+			Expect(x.Accepting(time.Now().AddDate(0, 0, -8))).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,160 @@
+package course
+
+import (
+	"context"
+	"mime/multipart"
+	"path"
+	"strings"
+
+	"github.com/acoshift/pgsql/pgctx"
+	"github.com/dustin/go-humanize"
+
+	"github.com/acoshift/acourse/internal/pkg/file"
+)
+
+// MaxAttachmentSize is the maximum size of an attachment file
+const MaxAttachmentSize = 100 << 20
+
+// Attachment is a downloadable file of course's content
+type Attachment struct {
+	ID          string
+	ContentID   string
+	Filename    string
+	Size        int64
+	DownloadURL string
+}
+
+// HumanSize returns attachment size in human readable format
+func (x *Attachment) HumanSize() string {
+	return humanize.Bytes(uint64(x.Size))
+}
+
+// AddAttachment uploads a file and attaches it to a course's content
+func AddAttachment(ctx context.Context, contentID string, fh *multipart.FileHeader) (string, error) {
+	if err := checkAttachment(fh); err != nil {
+		return "", err
+	}
+
+	// make sure content exists before upload
+	_, err := GetIDFromContent(ctx, contentID)
+	if err != nil {
+		return "", err
+	}
+
+	name, downloadURL, err := storeAttachment(ctx, fh)
+	if err != nil {
+		return "", err
+	}
+
+	var id string
+	// language=SQL
+	err = pgctx.QueryRow(ctx, `
+		insert into course_content_attachments
+			(
+				content_id,
+				i,
+				filename, size, download_url
+			)
+		values
+			(
+				$1,
+				(select coalesce(max(i)+1, 0) from course_content_attachments where content_id = $1),
+				$2, $3, $4
+			)
+		returning id
+	`, contentID, name, fh.Size, downloadURL).Scan(&id)
+	return id, err
+}
+
+// LinkAttachment attaches an already stored file to a course's content
+func LinkAttachment(ctx context.Context, contentID string, filename string, size int64, downloadURL string) (string, error) {
+	if filename == "" || downloadURL == "" {
+		return "", ErrInvalidAttachment
+	}
+
+	var id string
+	// language=SQL
+	err := pgctx.QueryRow(ctx, `
+		insert into course_content_attachments
+			(
+				content_id,
+				i,
+				filename, size, download_url
+			)
+		values
+			(
+				$1,
+				(select coalesce(max(i)+1, 0) from course_content_attachments where content_id = $1),
+				$2, $3, $4
+			)
+		returning id
+	`, contentID, filename, size, downloadURL).Scan(&id)
+	return id, err
+}
+
+// DeleteAttachment deletes a course's content attachment
+func DeleteAttachment(ctx context.Context, contentID, attachmentID string) error {
+	// language=SQL
+	_, err := pgctx.Exec(ctx, `
+		delete from course_content_attachments
+		where id = $1 and content_id = $2
+	`, attachmentID, contentID)
+	return err
+}
+
+// GetAttachments gets course's content attachments
+func GetAttachments(ctx context.Context, contentID string) ([]*Attachment, error) {
+	// language=SQL
+	rows, err := pgctx.Query(ctx, `
+		select id, content_id, filename, size, download_url
+		from course_content_attachments
+		where content_id = $1
+		order by i
+	`, contentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var xs []*Attachment
+	for rows.Next() {
+		var x Attachment
+		err = rows.Scan(&x.ID, &x.ContentID, &x.Filename, &x.Size, &x.DownloadURL)
+		if err != nil {
+			return nil, err
+		}
+		xs = append(xs, &x)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return xs, nil
+}
+
+func checkAttachment(fh *multipart.FileHeader) error {
+	if fh == nil || fh.Filename == "" {
+		return ErrInvalidAttachment
+	}
+	if fh.Size > MaxAttachmentSize {
+		return ErrAttachmentTooLarge
+	}
+	return nil
+}
+
+// storeAttachment uploads a file, returns the file's original name and its download url
+func storeAttachment(ctx context.Context, fh *multipart.FileHeader) (name string, downloadURL string, err error) {
+	fp, err := fh.Open()
+	if err != nil {
+		return "", "", err
+	}
+	defer fp.Close()
+
+	name = path.Base(strings.ReplaceAll(fh.Filename, "\\", "/"))
+	filename := file.GenerateFilename() + strings.ToLower(path.Ext(name))
+
+	downloadURL, err = file.StoreAttachment(ctx, fp, filename, name)
+	if err != nil {
+		return "", "", err
+	}
+	return name, downloadURL, nil
+}
@@ -0,0 +1,145 @@
+package course
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/acoshift/pgsql"
+	"github.com/acoshift/pgsql/pgctx"
+)
+
+// Category groups courses, categories are managed by admin
+type Category struct {
+	ID   string
+	Slug string
+	Name string
+}
+
+type CreateCategoryArgs struct {
+	Slug string
+	Name string
+}
+
+// CreateCategory creates new category at the end of the list
+func CreateCategory(ctx context.Context, m *CreateCategoryArgs) (string, error) {
+	if m.Name == "" || !ValidURL(m.Slug) {
+		return "", ErrInvalidCategory
+	}
+
+	var id string
+	// language=SQL
+	err := pgctx.QueryRow(ctx, `
+		insert into categories
+			(slug, name, i)
+		values
+			($1, $2, (select coalesce(max(i)+1, 0) from categories))
+		returning id
+	`, m.Slug, m.Name).Scan(&id)
+	if pgsql.IsUniqueViolation(err, "categories_slug_idx") {
+		return "", ErrCategorySlugNotAvailable
+	}
+	return id, err
+}
+
+type UpdateCategoryArgs struct {
+	ID   string
+	Slug string
+	Name string
+}
+
+// UpdateCategory updates a category
+func UpdateCategory(ctx context.Context, m *UpdateCategoryArgs) error {
+	if m.Name == "" || !ValidURL(m.Slug) {
+		return ErrInvalidCategory
+	}
+
+	// language=SQL
+	_, err := pgctx.Exec(ctx, `
+		update categories
+		set
+			slug = $2,
+			name = $3
+		where id = $1
+	`, m.ID, m.Slug, m.Name)
+	if pgsql.IsUniqueViolation(err, "categories_slug_idx") {
+		return ErrCategorySlugNotAvailable
+	}
+	return err
+}
+
+// DeleteCategory deletes a category, courses in the category will have no category
+func DeleteCategory(ctx context.Context, categoryID string) error {
+	return pgctx.RunInTx(ctx, func(ctx context.Context) error {
+		// language=SQL
+		_, err := pgctx.Exec(ctx, `
+			update courses
+			set category_id = null
+			where category_id = $1
+		`, categoryID)
+		if err != nil {
+			return err
+		}
+
+		// language=SQL
+		_, err = pgctx.Exec(ctx, `delete from categories where id = $1`, categoryID)
+		return err
+	})
+}
+
+// GetCategories gets all categories
+func GetCategories(ctx context.Context) ([]*Category, error) {
+	// language=SQL
+	rows, err := pgctx.Query(ctx, `
+		select id, slug, name
+		from categories
+		order by i
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var xs []*Category
+	for rows.Next() {
+		var x Category
+		err = rows.Scan(&x.ID, &x.Slug, &x.Name)
+		if err != nil {
+			return nil, err
+		}
+		xs = append(xs, &x)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return xs, nil
+}
+
+// GetCategoryIDBySlug gets category id from slug
+func GetCategoryIDBySlug(ctx context.Context, slug string) (categoryID string, err error) {
+	// language=SQL
+	err = pgctx.QueryRow(ctx, `
+		select id
+		from categories
+		where slug = $1
+	`, slug).Scan(&categoryID)
+	if err == sql.ErrNoRows {
+		err = ErrNotFound
+	}
+	return
+}
+
+// SetCategory sets course category, empty categoryID removes course from its category
+func SetCategory(ctx context.Context, courseID, categoryID string) error {
+	// language=SQL
+	_, err := pgctx.Exec(ctx, `
+		update courses
+		set
+			category_id = $2,
+			updated_at = now()
+		where id = $1
+	`, courseID, pgsql.NullString(&categoryID))
+	if pgsql.IsForeignKeyViolation(err) || pgsql.IsInvalidTextRepresentation(err) {
+		return ErrInvalidCategory
+	}
+	return err
+}
@@ -0,0 +1,243 @@
+package course
+
+import (
+	"context"
+	"time"
+
+	"github.com/acoshift/pgsql"
+	"github.com/acoshift/pgsql/pgctx"
+)
+
+// Clone copies course, its contents and assignments into a new course owned by newOwnerID
+// which starts at start, new course is private, has no url, and assignments' fixed due dates are removed
+func Clone(ctx context.Context, courseID, newOwnerID string, start time.Time) (string, error) {
+	if start.IsZero() {
+		return "", ErrStartRequired
+	}
+
+	c, err := Get(ctx, courseID)
+	if err != nil {
+		return "", err
+	}
+
+	var id string
+	err = pgctx.RunInTx(ctx, func(ctx context.Context) error {
+		// language=SQL
+		err := pgctx.QueryRow(ctx, `
+			insert into courses
+				(user_id, title, short_desc, long_desc, image, start, type, price, discount, enroll_detail)
+			values
+				($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			returning id
+		`,
+			newOwnerID, c.Title, c.ShortDesc, c.Desc, c.Image, start, c.Type, c.Price, c.Discount, c.EnrollDetail,
+		).Scan(&id)
+		if err != nil {
+			return err
+		}
+
+		err = SetOption(ctx, id, Option{
+			Attend:     c.Option.Attend,
+			Assignment: c.Option.Assignment,
+			Discount:   c.Option.Discount,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = SetCategory(ctx, id, c.Category.ID)
+		if err != nil {
+			return err
+		}
+
+		err = SetTags(ctx, id, c.Tags)
+		if err != nil {
+			return err
+		}
+
+		// language=SQL
+		_, err = pgctx.Exec(ctx, `
+			insert into course_prerequisites
+				(course_id, prerequisite_id, required)
+			select $2, prerequisite_id, required
+			from course_prerequisites
+			where course_id = $1
+		`, courseID, id)
+		if err != nil {
+			return err
+		}
+
+		sectionIDs, err := cloneSections(ctx, courseID, id)
+		if err != nil {
+			return err
+		}
+
+		err = cloneContents(ctx, courseID, id, sectionIDs)
+		if err != nil {
+			return err
+		}
+
+		err = UpdateSearchIndex(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// language=SQL
+		_, err = pgctx.Exec(ctx, `
+			insert into assignments
+				(course_id, i, title, long_desc, open, required, due_days, late_days)
+			select $2, i, title, long_desc, open, required, due_days, late_days
+			from assignments
+			where course_id = $1
+		`, courseID, id)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+// cloneSections copies sections from course to newCourseID, returns map of old section id to new section id
+func cloneSections(ctx context.Context, courseID, newCourseID string) (map[string]string, error) {
+	// language=SQL
+	rows, err := pgctx.Query(ctx, `
+		select id
+		from course_sections
+		where course_id = $1
+		order by i
+	`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	r := make(map[string]string)
+	for _, id := range ids {
+		var newID string
+		// language=SQL
+		err = pgctx.QueryRow(ctx, `
+			insert into course_sections
+				(course_id, i, title, long_desc)
+			select $2, i, title, long_desc
+			from course_sections
+			where id = $1
+			returning id
+		`, id, newCourseID).Scan(&newID)
+		if err != nil {
+			return nil, err
+		}
+		r[id] = newID
+	}
+	return r, nil
+}
+
+// cloneContents copies contents with their attachments and quizzes from course to newCourseID
+func cloneContents(ctx context.Context, courseID, newCourseID string, sectionIDs map[string]string) error {
+	type content struct {
+		ID        string
+		SectionID string
+	}
+
+	// language=SQL
+	rows, err := pgctx.Query(ctx, `
+		select id, section_id
+		from course_contents
+		where course_id = $1
+		order by i
+	`, courseID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var xs []*content
+	for rows.Next() {
+		var x content
+		err = rows.Scan(&x.ID, pgsql.NullString(&x.SectionID))
+		if err != nil {
+			return err
+		}
+		xs = append(xs, &x)
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	for _, x := range xs {
+		sectionID := sectionIDs[x.SectionID]
+
+		var newID string
+		// language=SQL
+		err = pgctx.QueryRow(ctx, `
+			insert into course_contents
+				(
+					course_id, section_id, i, title, long_desc, video_id, video_type, download_url,
+					release, release_days, release_notify
+				)
+			select
+				$2, $3, i, title, long_desc, video_id, video_type, download_url,
+				release, release_days, release_notify
+			from course_contents
+			where id = $1
+			returning id
+		`, x.ID, newCourseID, pgsql.NullString(&sectionID)).Scan(&newID)
+		if err != nil {
+			return err
+		}
+
+		// language=SQL
+		_, err = pgctx.Exec(ctx, `
+			insert into course_content_attachments
+				(content_id, i, filename, size, download_url)
+			select $2, i, filename, size, download_url
+			from course_content_attachments
+			where content_id = $1
+		`, x.ID, newID)
+		if err != nil {
+			return err
+		}
+
+		// language=SQL
+		_, err = pgctx.Exec(ctx, `
+			insert into quizzes
+				(content_id, title, pass_mark, max_attempts)
+			select $2, title, pass_mark, max_attempts
+			from quizzes
+			where content_id = $1
+		`, x.ID, newID)
+		if err != nil {
+			return err
+		}
+
+		// language=SQL
+		_, err = pgctx.Exec(ctx, `
+			insert into quiz_questions
+				(quiz_id, i, type, question, choices, corrects, answers, match)
+			select (select id from quizzes where content_id = $2), i, type, question, choices, corrects, answers, match
+			from quiz_questions
+			where quiz_id = (select id from quizzes where content_id = $1)
+		`, x.ID, newID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,20 @@
+package course_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/acoshift/acourse/internal/pkg/course"
+)
+
+var _ = Describe("Clone", func() {
+	It("should require start date of the new course", func() {
+		id, err := Clone(context.Background(), "course", "user", time.Time{})
+
+		Expect(err).To(Equal(ErrStartRequired))
+		Expect(id).To(BeZero())
+	})
+})
@@ -0,0 +1,43 @@
+package course
+
+import (
+	"context"
+
+	"github.com/acoshift/pgsql/pgctx"
+)
+
+// IsCompleted checks is user completed all contents and passed all required assignments of a course,
+// the course is completed when user can be issued a certificate
+func IsCompleted(ctx context.Context, userID, courseID string) (bool, error) {
+	var completed bool
+	// language=SQL
+	err := pgctx.QueryRow(ctx, `
+		select
+			exists (
+				select 1
+				from course_contents
+				where course_id = $2
+			)
+			and not exists (
+				select 1
+				from course_contents c
+					left join progresses p on p.content_id = c.id and p.user_id = $1
+				where c.course_id = $2 and p.completed_at is null
+			)
+			and not exists (
+				select 1
+				from assignments a
+				where a.course_id = $2
+				  and a.required
+				  and not exists (
+					select 1
+					from user_assignments s
+					where s.assignment_id = a.id
+					  and s.user_id = $1
+					  and s.graded_at is not null
+					  and s.passed
+				  )
+			)
+	`, userID, courseID).Scan(&completed)
+	return completed, err
+}
@@ -4,80 +4,177 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
+	"html/template"
 	"io"
+	"mime/multipart"
 
+	"github.com/acoshift/pgsql"
 	"github.com/acoshift/pgsql/pgctx"
 
 	"github.com/acoshift/acourse/internal/pkg/file"
 	"github.com/acoshift/acourse/internal/pkg/image"
+	"github.com/acoshift/acourse/internal/pkg/video"
 )
 
 // Content type
 type Content struct {
 	ID          string
 	CourseID    string
+	SectionID   string
 	Title       string
 	Desc        string
 	VideoID     string
 	VideoType   int
 	DownloadURL string
+
+	Release       int  // release rule
+	ReleaseDays   int  // days after release rule's date
+	ReleaseNotify bool // email enrolled users when content is released
+}
+
+// VideoPlayer renders content's video player
+func (x *Content) VideoPlayer() template.HTML {
+	return video.Embed(x.videoType(), x.VideoID)
+}
+
+// VideoProvider returns content's video provider name
+func (x *Content) VideoProvider() string {
+	return video.Name(x.videoType())
+}
+
+func (x *Content) videoType() int {
+	// contents created before video providers have no video type
+	if x.VideoType == 0 {
+		return video.Youtube
+	}
+	return x.VideoType
 }
 
 type CreateContentArgs struct {
 	ID        string
+	SectionID string
 	Title     string
 	LongDesc  string
 	VideoID   string
 	VideoType int
+	VideoFile *multipart.FileHeader
+
+	Release       int
+	ReleaseDays   int
+	ReleaseNotify bool
 }
 
 // CreateContent creates new course content
 func CreateContent(ctx context.Context, m *CreateContentArgs) (string, error) {
 	// TODO: validate instructor
 
+	if !validRelease(m.Release, m.ReleaseDays) {
+		return "", ErrInvalidRelease
+	}
+
+	err := checkSection(ctx, m.ID, m.SectionID)
+	if err != nil {
+		return "", err
+	}
+
+	videoID, err := resolveVideo(ctx, m.VideoType, m.VideoID, m.VideoFile)
+	if err != nil {
+		return "", err
+	}
+
 	var contentID string
-	err := pgctx.QueryRow(ctx, `
-		insert into course_contents
-			(
-				course_id,
-				i,
-				title, long_desc, video_id, video_type
-			)
-		values
-			(
-				$1,
-				(select coalesce(max(i)+1, 0) from course_contents where course_id = $1),
-				$2, $3, $4, $5
-			)
-		returning id
-	`,
-		m.ID,
-		m.Title, m.LongDesc, m.VideoID, m.VideoType,
-	).Scan(&contentID)
+	err = pgctx.RunInTx(ctx, func(ctx context.Context) error {
+		err := pgctx.QueryRow(ctx, `
+			insert into course_contents
+				(
+					course_id, section_id,
+					i,
+					title, long_desc, video_id, video_type,
+					release, release_days, release_notify
+				)
+			values
+				(
+					$1, $2,
+					(select coalesce(max(i)+1, 0) from course_contents where course_id = $1),
+					$3, $4, $5, $6,
+					$7, $8, $9
+				)
+			returning id
+		`,
+			m.ID, pgsql.NullString(&m.SectionID),
+			m.Title, m.LongDesc, videoID, m.VideoType,
+			m.Release, m.ReleaseDays, m.ReleaseNotify,
+		).Scan(&contentID)
+		if err != nil {
+			return err
+		}
+
+		return UpdateSearchIndex(ctx, m.ID)
+	})
 	return contentID, err
 }
 
 type UpdateContentArgs struct {
 	ContentID string
+	SectionID string
 	Title     string
 	Desc      string
 	VideoID   string
+	VideoType int
+	VideoFile *multipart.FileHeader
+
+	Release       int
+	ReleaseDays   int
+	ReleaseNotify bool
 }
 
 // UpdateContent updates a course content
 func UpdateContent(ctx context.Context, m *UpdateContentArgs) error {
 	// TODO: validate ownership
 
-	_, err := pgctx.Exec(ctx, `
-		update course_contents
-		set
-			title = $2,
-			long_desc = $3,
-			video_id = $4,
-			updated_at = now()
-		where id = $1
-	`, m.ContentID, m.Title, m.Desc, m.VideoID)
-	return err
+	if !validRelease(m.Release, m.ReleaseDays) {
+		return ErrInvalidRelease
+	}
+
+	courseID, err := GetIDFromContent(ctx, m.ContentID)
+	if err != nil {
+		return err
+	}
+
+	err = checkSection(ctx, courseID, m.SectionID)
+	if err != nil {
+		return err
+	}
+
+	videoID, err := resolveVideo(ctx, m.VideoType, m.VideoID, m.VideoFile)
+	if err != nil {
+		return err
+	}
+
+	return pgctx.RunInTx(ctx, func(ctx context.Context) error {
+		_, err := pgctx.Exec(ctx, `
+			update course_contents
+			set
+				section_id = $2,
+				title = $3,
+				long_desc = $4,
+				video_id = $5,
+				video_type = $6,
+				release = $7,
+				release_days = $8,
+				release_notify = $9,
+				updated_at = now()
+			where id = $1
+		`,
+			m.ContentID, pgsql.NullString(&m.SectionID), m.Title, m.Desc, videoID, m.VideoType,
+			m.Release, m.ReleaseDays, m.ReleaseNotify,
+		)
+		if err != nil {
+			return err
+		}
+
+		return UpdateSearchIndex(ctx, courseID)
+	})
 }
 
 // GetContent gets a course's content
@@ -85,11 +182,13 @@ func GetContent(ctx context.Context, contentID string) (*Content, error) {
 	var x Content
 	err := pgctx.QueryRow(ctx, `
 		select
-			id, course_id, title, long_desc, video_id, video_type, download_url
+			id, course_id, section_id, title, long_desc, video_id, video_type, download_url,
+			release, release_days, release_notify
 		from course_contents
 		where id = $1
 	`, contentID).Scan(
-		&x.ID, &x.CourseID, &x.Title, &x.Desc, &x.VideoID, &x.VideoType, &x.DownloadURL,
+		&x.ID, &x.CourseID, pgsql.NullString(&x.SectionID), &x.Title, &x.Desc, &x.VideoID, &x.VideoType, &x.DownloadURL,
+		&x.Release, &x.ReleaseDays, &x.ReleaseNotify,
 	)
 	if err == sql.ErrNoRows {
 		return nil, ErrNotFound
@@ -100,23 +199,79 @@ func GetContent(ctx context.Context, contentID string) (*Content, error) {
 	return &x, nil
 }
 
-// DeleteContent deletes a course's content
-func DeleteContent(ctx context.Context, contentID string) error {
-	// TODO: validate ownership
+// DeleteContent deletes a course's content with its progresses, quiz, comments and attachments
+func DeleteContent(ctx context.Context, courseID, contentID string) error {
+	return pgctx.RunInTx(ctx, func(ctx context.Context) error {
+		var ok bool
+		// language=SQL
+		err := pgctx.QueryRow(ctx, `
+			select true
+			from course_contents
+			where id = $1 and course_id = $2
+			for update
+		`, contentID, courseID).Scan(&ok)
+		if err == sql.ErrNoRows || pgsql.IsInvalidTextRepresentation(err) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = pgctx.Exec(ctx, `delete from progresses where content_id = $1`, contentID)
+		if err != nil {
+			return err
+		}
+
+		_, err = pgctx.Exec(ctx, `delete from content_release_notifies where content_id = $1`, contentID)
+		if err != nil {
+			return err
+		}
+
+		_, err = pgctx.Exec(ctx, `delete from course_content_attachments where content_id = $1`, contentID)
+		if err != nil {
+			return err
+		}
 
-	_, err := pgctx.Exec(ctx, `delete from course_contents where id = $1`, contentID)
-	return err
+		_, err = pgctx.Exec(ctx, `delete from content_comments where content_id = $1`, contentID)
+		if err != nil {
+			return err
+		}
+
+		_, err = pgctx.Exec(ctx, `delete from quiz_attempts where quiz_id in (select id from quizzes where content_id = $1)`, contentID)
+		if err != nil {
+			return err
+		}
+
+		_, err = pgctx.Exec(ctx, `delete from quiz_questions where quiz_id in (select id from quizzes where content_id = $1)`, contentID)
+		if err != nil {
+			return err
+		}
+
+		_, err = pgctx.Exec(ctx, `delete from quizzes where content_id = $1`, contentID)
+		if err != nil {
+			return err
+		}
+
+		_, err = pgctx.Exec(ctx, `delete from course_contents where id = $1 and course_id = $2`, contentID, courseID)
+		if err != nil {
+			return err
+		}
+
+		return UpdateSearchIndex(ctx, courseID)
+	})
 }
 
-// GetContents gets course's contents
+// GetContents gets course's contents ordered by section
 func GetContents(ctx context.Context, id string) ([]*Content, error) {
 	// language=SQL
 	rows, err := pgctx.Query(ctx, `
 		select
-			id, course_id, title, long_desc, video_id, video_type, download_url
-		from course_contents
-		where course_id = $1
-		order by i
+			c.id, c.course_id, c.section_id, c.title, c.long_desc, c.video_id, c.video_type, c.download_url,
+			c.release, c.release_days, c.release_notify
+		from course_contents as c
+			left join course_sections as s on s.id = c.section_id
+		where c.course_id = $1
+		order by s.i nulls first, c.i
 	`, id)
 	if err != nil {
 		return nil, err
@@ -127,7 +282,8 @@ func GetContents(ctx context.Context, id string) ([]*Content, error) {
 	for rows.Next() {
 		var x Content
 		err = rows.Scan(
-			&x.ID, &x.CourseID, &x.Title, &x.Desc, &x.VideoID, &x.VideoType, &x.DownloadURL,
+			&x.ID, &x.CourseID, pgsql.NullString(&x.SectionID), &x.Title, &x.Desc, &x.VideoID, &x.VideoType, &x.DownloadURL,
+			&x.Release, &x.ReleaseDays, &x.ReleaseNotify,
 		)
 		if err != nil {
 			return nil, err
@@ -140,6 +296,32 @@ func GetContents(ctx context.Context, id string) ([]*Content, error) {
 	return xs, nil
 }
 
+// resolveVideo uploads video file if any, then parses video id using video type's provider
+func resolveVideo(ctx context.Context, videoType int, videoID string, videoFile *multipart.FileHeader) (string, error) {
+	if videoFile != nil && videoType == video.File {
+		ext, err := video.FileExt(videoFile)
+		if err != nil {
+			return "", err
+		}
+
+		fp, err := videoFile.Open()
+		if err != nil {
+			return "", err
+		}
+		defer fp.Close()
+
+		videoID, err = file.Store(ctx, fp, file.GenerateFilename()+ext, false)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if videoID == "" {
+		return "", nil
+	}
+	return video.Parse(videoType, videoID)
+}
+
 func uploadCourseCoverImage(ctx context.Context, r io.Reader) (string, error) {
 	buf := &bytes.Buffer{}
 	err := image.JPEG(buf, r, 1200, 0, 90, false)
@@ -163,8 +345,14 @@ func GetIDFromContent(ctx context.Context, contentID string) (courseID string, e
 		from course_contents
 		where id = $1
 	`, contentID).Scan(&courseID)
-	if err == sql.ErrNoRows {
+	if err == sql.ErrNoRows || pgsql.IsInvalidTextRepresentation(err) {
 		err = ErrNotFound
 	}
 	return
 }
+
+// ReorderContents sets the order of course's contents to match contentIDs,
+// contentIDs must contain every content of the course exactly once
+func ReorderContents(ctx context.Context, courseID string, contentIDs []string) error {
+	return reorder(ctx, "course_contents", courseID, contentIDs, ErrInvalidContents)
+}
@@ -6,11 +6,15 @@ import (
 	"database/sql"
 	"encoding/gob"
 	"fmt"
+	"io"
+	"math"
 	"mime/multipart"
+	"net/url"
 	"time"
 
 	"github.com/acoshift/pgsql"
 	"github.com/acoshift/pgsql/pgctx"
+	"github.com/lib/pq"
 
 	"github.com/acoshift/acourse/internal/pkg/context/redisctx"
 	"github.com/acoshift/acourse/internal/pkg/image"
@@ -35,6 +39,15 @@ type Course struct {
 	Price        float64
 	Discount     float64
 	EnrollDetail string
+	ArchivedAt   time.Time
+	Category     Category
+	Tags         []string
+	Rating       Rating
+}
+
+// Archived returns true if course was archived
+func (x *Course) Archived() bool {
+	return !x.ArchivedAt.IsZero()
 }
 
 // Link returns id if url is invalid
@@ -62,12 +75,6 @@ const (
 	EBook
 )
 
-// Video type values
-const (
-	_ = iota
-	Youtube
-)
-
 type CreateArgs struct {
 	UserID    string
 	Title     string
@@ -117,18 +124,36 @@ func Create(ctx context.Context, m *CreateArgs) (string, error) {
 			return err
 		}
 
+		err = UpdateSearchIndex(ctx, id)
+		if err != nil {
+			return err
+		}
+
 		return SetOption(ctx, id, Option{})
 	})
 	return id, err
 }
 
+// MaxPrice is the highest price which can be stored in decimal(9,2) column
+const MaxPrice = 9999999.99
+
+// validPrice checks is price a finite non-negative number which fits the price column
+func validPrice(p float64) bool {
+	return !math.IsNaN(p) && p >= 0 && p <= MaxPrice
+}
+
 type UpdateArgs struct {
-	ID        string
-	Title     string
-	ShortDesc string
-	LongDesc  string
-	Image     *multipart.FileHeader
-	Start     time.Time
+	ID           string
+	Title        string
+	ShortDesc    string
+	LongDesc     string
+	Image        *multipart.FileHeader
+	Start        time.Time
+	Type         int
+	Price        float64
+	Discount     float64
+	UseDiscount  bool
+	EnrollDetail string
 }
 
 // Update updates course
@@ -139,6 +164,15 @@ func Update(ctx context.Context, m *UpdateArgs) error {
 	if m.Title == "" {
 		return fmt.Errorf("title required")
 	}
+	if m.Type != Live && m.Type != Video && m.Type != EBook {
+		return ErrInvalidType
+	}
+	if !validPrice(m.Price) {
+		return ErrInvalidPrice
+	}
+	if !validPrice(m.Discount) || (m.UseDiscount && m.Discount > m.Price) {
+		return ErrInvalidDiscount
+	}
 
 	var imageURL string
 	if m.Image != nil {
@@ -161,6 +195,7 @@ func Update(ctx context.Context, m *UpdateArgs) error {
 	}
 
 	err := pgctx.RunInTx(ctx, func(ctx context.Context) error {
+		// language=SQL
 		_, err := pgctx.Exec(ctx, `
 			update courses
 			set
@@ -168,9 +203,31 @@ func Update(ctx context.Context, m *UpdateArgs) error {
 				short_desc = $3,
 				long_desc = $4,
 				start = $5,
+				type = $6,
+				price = $7,
+				discount = $8,
+				enroll_detail = $9,
 				updated_at = now()
 			where id = $1
-		`, m.ID, m.Title, m.ShortDesc, m.LongDesc, pgsql.NullTime(&m.Start))
+		`,
+			m.ID, m.Title, m.ShortDesc, m.LongDesc, pgsql.NullTime(&m.Start),
+			m.Type, m.Price, m.Discount, m.EnrollDetail,
+		)
+		if err != nil {
+			return err
+		}
+
+		err = UpdateSearchIndex(ctx, m.ID)
+		if err != nil {
+			return err
+		}
+
+		// language=SQL
+		_, err = pgctx.Exec(ctx, `
+			update course_options
+			set discount = $2
+			where course_id = $1
+		`, m.ID, m.UseDiscount)
 		if err != nil {
 			return err
 		}
@@ -215,6 +272,15 @@ func SetImage(ctx context.Context, id string, img string) error {
 	return err
 }
 
+// SetCoverImage resizes and uploads image from r, then sets it as course image
+func SetCoverImage(ctx context.Context, id string, r io.Reader) error {
+	imageURL, err := uploadCourseCoverImage(ctx, r)
+	if err != nil {
+		return err
+	}
+	return SetImage(ctx, id, imageURL)
+}
+
 // GetURL gets course url
 func GetURL(ctx context.Context, id string) (string, error) {
 	var r string
@@ -241,18 +307,27 @@ func Get(ctx context.Context, id string) (*Course, error) {
 	// language=SQL
 	err := pgctx.QueryRow(ctx, `
 		select c. id, c.title, c.short_desc, c.long_desc, c.image,
-		       c.start, c.url, c.type, c.price, c.discount, c.enroll_detail,
+		       c.start, c.url, c.type, c.price, c.discount, c.enroll_detail, c.archived_at,
 		       u.id, u.name, u.image,
-		       opt.public, opt.enroll, opt.attend, opt.assignment, opt.discount
+		       opt.public, opt.enroll, opt.attend, opt.assignment, opt.discount,
+		       cat.id, cat.slug, cat.name,
+		       array(select tag from course_tags where course_id = c.id order by tag),
+		       coalesce((select avg(rating) from reviews where course_id = c.id and not hidden), 0),
+		       (select count(*) from reviews where course_id = c.id and not hidden)
 		from courses as c
 			left join course_options as opt on opt.course_id = c.id
 			left join users as u on u.id = c.user_id
+			left join categories as cat on cat.id = c.category_id
 		where c.id = $1
 	`, id).Scan(
 		&x.ID, &x.Title, &x.ShortDesc, &x.Desc, &x.Image,
 		pgsql.NullTime(&x.Start), pgsql.NullString(&x.URL), &x.Type, &x.Price, &x.Discount, &x.EnrollDetail,
+		pgsql.NullTime(&x.ArchivedAt),
 		&x.Owner.ID, &x.Owner.Name, &x.Owner.Image,
 		&x.Option.Public, &x.Option.Enroll, &x.Option.Attend, &x.Option.Assignment, &x.Option.Discount,
+		pgsql.NullString(&x.Category.ID), pgsql.NullString(&x.Category.Slug), pgsql.NullString(&x.Category.Name),
+		pq.Array(&x.Tags),
+		&x.Rating.Average, &x.Rating.Count,
 	)
 	if err == sql.ErrNoRows {
 		return nil, ErrNotFound
@@ -288,6 +363,20 @@ type PublicCard struct {
 	Type     int
 	Price    float64
 	Discount float64
+	Category Category
+	Tags     []string
+	Rating   Rating
+}
+
+// Rating is the aggregate of course's visible reviews
+type Rating struct {
+	Average float64
+	Count   int64
+}
+
+// Stars returns average rating rounded to whole stars
+func (x Rating) Stars() int {
+	return int(math.Round(x.Average))
 }
 
 // Link returns course link
@@ -303,13 +392,103 @@ func (x *PublicCard) ShowStart() bool {
 	return x.Type == Live && !x.Start.IsZero()
 }
 
-func GetPublicCards(ctx context.Context) ([]*PublicCard, error) {
+// Price filter values
+const (
+	_ = iota
+	Free
+	Paid
+)
+
+// Public course sorts
+const (
+	// SortDefault shows live courses first, then newest courses
+	SortDefault = iota
+	SortNewest
+	SortStart
+	SortPopular
+	SortPrice
+)
+
+// PublicFilter filters public courses, zero value field does not filter
+type PublicFilter struct {
+	Category string // category slug
+	Tag      string
+	Type     int
+	Price    int
+	Sort     int
+}
+
+func (f *PublicFilter) cacheKey() string {
+	return fmt.Sprintf("%s:%s:%d:%d", f.Category, url.QueryEscape(f.Tag), f.Type, f.Price)
+}
+
+// language=SQL
+const publicCardsWhere = `
+	where opt.public = true and c.archived_at is null
+		and ($1 = '' or cat.slug = $1)
+		and ($2 = '' or exists (select 1 from course_tags where course_id = c.id and tag = $2))
+		and ($3 = 0 or c.type = $3)
+		and ($4 = 0 or ($4 = $5 and c.price = 0) or ($4 = $6 and c.price > 0))
+`
+
+// CountPublicCards counts public courses which match filter
+func CountPublicCards(ctx context.Context, f *PublicFilter) (cnt int64, err error) {
+	c := redisctx.GetClient(ctx)
+	cacheKey := redisctx.GetPrefix(ctx) + "cache:count_public_course:" + f.cacheKey()
+
+	cnt, err = c.Get(ctx, cacheKey).Int64()
+	if err == nil {
+		return
+	}
+
+	// language=SQL
+	err = pgctx.QueryRow(ctx, `
+		select count(*)
+		from courses as c
+			left join course_options as opt on c.id = opt.course_id
+			left join categories as cat on cat.id = c.category_id
+	`+publicCardsWhere, f.Category, f.Tag, f.Type, f.Price, Free, Paid).Scan(&cnt)
+	if err != nil {
+		return
+	}
+
+	go c.Set(ctx, cacheKey, cnt, time.Minute)
+
+	return
+}
+
+// publicCardsOrder returns order by clause for sort
+func publicCardsOrder(sort int) string {
+	switch sort {
+	case SortNewest:
+		return `order by c.created_at desc`
+	case SortStart:
+		return `order by c.start is null or c.start < now(), c.start, c.created_at desc`
+	case SortPopular:
+		return `order by (select count(*) from enrolls where course_id = c.id) desc, c.created_at desc`
+	case SortPrice:
+		return `order by case when opt.discount then c.discount else c.price end, c.created_at desc`
+	default:
+		return `
+			order by
+				case
+					when c.type = 1 then 1
+					else null
+				end,
+				c.created_at desc
+		`
+	}
+}
+
+// GetPublicCards gets a page of public courses which match filter
+func GetPublicCards(ctx context.Context, f *PublicFilter, limit, offset int64) ([]*PublicCard, error) {
 	c := redisctx.GetClient(ctx)
 	cachePrefix := redisctx.GetPrefix(ctx)
+	cacheKey := cachePrefix + "cache:list_public_course:" + f.cacheKey() + fmt.Sprintf(":%d:%d:%d", f.Sort, limit, offset)
 
 	// look from cache
 	{
-		bs, err := c.Get(ctx, cachePrefix+"cache:list_public_course").Bytes()
+		bs, err := c.Get(ctx, cacheKey).Bytes()
 		if err == nil {
 			var xs []*PublicCard
 			err = gob.NewDecoder(bytes.NewReader(bs)).Decode(&xs)
@@ -325,17 +504,17 @@ func GetPublicCards(ctx context.Context) ([]*PublicCard, error) {
 			c.id,
 			c.title, c.short_desc, c.image, c.start, c.url,
 			c.type, c.price, c.discount,
-			opt.public, opt.enroll, opt.attend, opt.assignment, opt.discount
+			opt.public, opt.enroll, opt.attend, opt.assignment, opt.discount,
+			cat.id, cat.slug, cat.name,
+			array(select tag from course_tags where course_id = c.id order by tag),
+			coalesce((select avg(rating) from reviews where course_id = c.id and not hidden), 0),
+			(select count(*) from reviews where course_id = c.id and not hidden)
 		from courses as c
 			left join course_options as opt on c.id = opt.course_id
-		where opt.public = true
-		order by
-			case
-				when c.type = 1 then 1
-				else null
-			end,
-			c.created_at desc
-	`)
+			left join categories as cat on cat.id = c.category_id
+	`+publicCardsWhere+publicCardsOrder(f.Sort)+`
+		limit $7 offset $8
+	`, f.Category, f.Tag, f.Type, f.Price, Free, Paid, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -349,6 +528,9 @@ func GetPublicCards(ctx context.Context) ([]*PublicCard, error) {
 			&x.Title, &x.Desc, &x.Image, pgsql.NullTime(&x.Start), pgsql.NullString(&x.URL),
 			&x.Type, &x.Price, &x.Discount,
 			&x.Option.Public, &x.Option.Enroll, &x.Option.Attend, &x.Option.Assignment, &x.Option.Discount,
+			pgsql.NullString(&x.Category.ID), pgsql.NullString(&x.Category.Slug), pgsql.NullString(&x.Category.Name),
+			pq.Array(&x.Tags),
+			&x.Rating.Average, &x.Rating.Count,
 		)
 		if err != nil {
 			return nil, err
@@ -365,7 +547,7 @@ func GetPublicCards(ctx context.Context) ([]*PublicCard, error) {
 		buf := bytes.Buffer{}
 		err := gob.NewEncoder(&buf).Encode(xs)
 		if err == nil {
-			c.Set(ctx, cachePrefix+"cache:list_public_course", buf.Bytes(), time.Minute)
+			c.Set(ctx, cacheKey, buf.Bytes(), time.Minute)
 		}
 	}()
 
@@ -0,0 +1,13 @@
+package course_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestCourse(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Course Suite")
+}
@@ -0,0 +1,72 @@
+package course_test
+
+import (
+	"context"
+	"math"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/acoshift/acourse/internal/pkg/course"
+)
+
+var _ = Describe("Course", func() {
+	Describe("Update", func() {
+		var args UpdateArgs
+
+		BeforeEach(func() {
+			args = UpdateArgs{
+				ID:    "course",
+				Title: "title",
+				Type:  Video,
+			}
+		})
+
+		It("should reject negative price", func() {
+			args.Price = -1
+
+			Expect(Update(context.Background(), &args)).To(Equal(ErrInvalidPrice))
+		})
+
+		It("should reject not a number price", func() {
+			args.Price = math.NaN()
+
+			Expect(Update(context.Background(), &args)).To(Equal(ErrInvalidPrice))
+		})
+
+		It("should reject infinite price", func() {
+			args.Price = math.Inf(1)
+
+			Expect(Update(context.Background(), &args)).To(Equal(ErrInvalidPrice))
+		})
+
+		It("should reject price which does not fit the price column", func() {
+			args.Price = 10000000
+
+			Expect(Update(context.Background(), &args)).To(Equal(ErrInvalidPrice))
+		})
+
+		It("should reject not a number discount", func() {
+			args.Price = 100
+			args.Discount = math.NaN()
+			args.UseDiscount = true
+
+			Expect(Update(context.Background(), &args)).To(Equal(ErrInvalidDiscount))
+		})
+
+		It("should reject not a number discount even it is not used", func() {
+			args.Price = 100
+			args.Discount = math.NaN()
+
+			Expect(Update(context.Background(), &args)).To(Equal(ErrInvalidDiscount))
+		})
+
+		It("should reject discount higher than price", func() {
+			args.Price = 100
+			args.Discount = 200
+			args.UseDiscount = true
+
+			Expect(Update(context.Background(), &args)).To(Equal(ErrInvalidDiscount))
+		})
+	})
+})
@@ -2,7 +2,10 @@ package course
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
+	"github.com/acoshift/pgsql"
 	"github.com/acoshift/pgsql/pgctx"
 )
 
@@ -32,3 +35,87 @@ func IsEnroll(ctx context.Context, userID, courseID string) (bool, error) {
 	`, userID, courseID).Scan(&b)
 	return b, err
 }
+
+// GetEnrolledAt gets the time when user enrolled a course
+func GetEnrolledAt(ctx context.Context, userID, courseID string) (time.Time, error) {
+	var t time.Time
+
+	// language=SQL
+	err := pgctx.QueryRow(ctx, `
+		select created_at
+		from enrolls
+		where user_id = $1 and course_id = $2
+	`, userID, courseID).Scan(&t)
+	if err == sql.ErrNoRows {
+		return t, ErrNotFound
+	}
+	return t, err
+}
+
+// Student is an enrolled user
+type Student struct {
+	User struct {
+		ID       string
+		Username string
+		Name     string
+		Email    string
+		Image    string
+	}
+	EnrolledAt time.Time
+	Payment    struct {
+		Price  float64
+		Code   string
+		Status int
+		At     time.Time
+	}
+}
+
+// Paid returns true if student enrolled by payment
+func (x *Student) Paid() bool {
+	return !x.Payment.At.IsZero()
+}
+
+// GetStudents gets enrolled users of a course with their latest payment, newest first
+func GetStudents(ctx context.Context, courseID string) ([]*Student, error) {
+	// language=SQL
+	rows, err := pgctx.Query(ctx, `
+		select
+			u.id, u.username, u.name, coalesce(u.email, ''), u.image,
+			e.created_at,
+			coalesce(p.price, 0), coalesce(p.code, ''), coalesce(p.status, 0), p.created_at
+		from enrolls as e
+			left join users as u on u.id = e.user_id
+			left join lateral (
+				select price, code, status, created_at
+				from payments
+				where user_id = e.user_id and course_id = e.course_id
+				order by created_at desc
+				limit 1
+			) as p on true
+		where e.course_id = $1
+		order by e.created_at desc
+	`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var xs []*Student
+	for rows.Next() {
+		var x Student
+		err = rows.Scan(
+			&x.User.ID, &x.User.Username, &x.User.Name, &x.User.Email, &x.User.Image,
+			&x.EnrolledAt,
+			&x.Payment.Price, &x.Payment.Code, &x.Payment.Status, pgsql.NullTime(&x.Payment.At),
+		)
+		if err != nil {
+			return nil, err
+		}
+		xs = append(xs, &x)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return xs, nil
+}
@@ -5,5 +5,34 @@ import (
 )
 
 var (
-	ErrNotFound = errors.New("course: not found")
+	ErrNotFound        = errors.New("course: not found")
+	ErrInvalidType     = errors.New("course: invalid type")
+	ErrInvalidPrice    = errors.New("course: invalid price")
+	ErrInvalidDiscount = errors.New("course: invalid discount")
+	ErrInvalidURL      = errors.New("course: invalid url")
+	ErrURLNotAvailable = errors.New("course: url not available")
+	ErrInvalidContents = errors.New("course: invalid contents")
+	ErrInvalidSections = errors.New("course: invalid sections")
+	ErrHasEnrolls      = errors.New("course: has enrolls")
+	ErrInvalidTags     = errors.New("course: invalid tags")
+	ErrStartRequired   = errors.New("course: start required")
+
+	ErrInvalidCategory          = errors.New("course: invalid category")
+	ErrCategorySlugNotAvailable = errors.New("course: category slug not available")
+
+	ErrInvalidAttachment  = errors.New("course: invalid attachment")
+	ErrAttachmentTooLarge = errors.New("course: attachment too large")
+
+	ErrInvalidAssignments       = errors.New("course: invalid assignments")
+	ErrAssignmentHasSubmissions = errors.New("course: assignment has submissions")
+	ErrAssignmentClosed         = errors.New("course: assignment closed")
+
+	ErrInvalidRole    = errors.New("course: invalid role")
+	ErrUserNotFound   = errors.New("course: user not found")
+	ErrAlreadyMember  = errors.New("course: already member")
+	ErrMemberNotFound = errors.New("course: member not found")
+
+	ErrInvalidPrerequisite = errors.New("course: invalid prerequisite")
+
+	ErrInvalidRelease = errors.New("course: invalid release")
 )
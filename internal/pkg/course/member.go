@@ -0,0 +1,205 @@
+package course
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/acoshift/pgsql"
+	"github.com/acoshift/pgsql/pgctx"
+)
+
+// Role is user's role in a course, zero role is not a member
+type Role int
+
+// Roles, owner is the course's user and is not stored in course members
+const (
+	RoleOwner Role = iota + 1
+	RoleInstructor
+	RoleAssistant
+)
+
+// Permission is an action which course's member can do in editor
+type Permission int
+
+// Permissions
+const (
+	EditContent Permission = iota + 1 // edit course, sections, contents and assignments
+	Grade                             // grade assignment submissions
+	ViewRoster                        // view enrolled students and their payments
+	Manage                            // manage course setting and members
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleOwner:      {EditContent, Grade, ViewRoster, Manage},
+	RoleInstructor: {EditContent, Grade, ViewRoster},
+	RoleAssistant:  {Grade, ViewRoster},
+}
+
+// Can returns true if role has permission
+func (r Role) Can(p Permission) bool {
+	for _, x := range rolePermissions[r] {
+		if x == p {
+			return true
+		}
+	}
+	return false
+}
+
+// IsMember returns true if role is a course's member role
+func (r Role) IsMember() bool {
+	return rolePermissions[r] != nil
+}
+
+// Member is a user who helps teaching a course
+type Member struct {
+	User struct {
+		ID       string
+		Username string
+		Name     string
+		Image    string
+	}
+	Role      Role
+	CreatedAt time.Time
+}
+
+// GetRole gets user's role in a course, returns zero role if user is not a member
+func GetRole(ctx context.Context, courseID, userID string) (Role, error) {
+	var r Role
+	// language=SQL
+	err := pgctx.QueryRow(ctx, `
+		select
+			case
+				when c.user_id = $2 then $3::int
+				else coalesce((select role from course_members where course_id = c.id and user_id = $2), 0)
+			end
+		from courses as c
+		where c.id = $1
+	`, courseID, userID, RoleOwner).Scan(&r)
+	if err == sql.ErrNoRows || pgsql.IsInvalidTextRepresentation(err) {
+		return 0, ErrNotFound
+	}
+	return r, err
+}
+
+// Can checks is user has permission in a course
+func Can(ctx context.Context, courseID, userID string, p Permission) (bool, error) {
+	r, err := GetRole(ctx, courseID, userID)
+	if err != nil {
+		return false, err
+	}
+	return r.Can(p), nil
+}
+
+// GetMembers gets course's members, owner first
+func GetMembers(ctx context.Context, courseID string) ([]*Member, error) {
+	// language=SQL
+	rows, err := pgctx.Query(ctx, `
+		select u.id, u.username, u.name, u.image, $2::int, c.created_at
+		from courses as c
+			left join users as u on u.id = c.user_id
+		where c.id = $1
+		union all
+		select * from (
+			select u.id, u.username, u.name, u.image, m.role, m.created_at
+			from course_members as m
+				left join users as u on u.id = m.user_id
+			where m.course_id = $1
+			order by m.role, m.created_at
+		) as m
+	`, courseID, RoleOwner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var xs []*Member
+	for rows.Next() {
+		var x Member
+		err = rows.Scan(
+			&x.User.ID, &x.User.Username, &x.User.Name, &x.User.Image, &x.Role, &x.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		xs = append(xs, &x)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return xs, nil
+}
+
+// AddMember adds user as course's co-instructor or assistant
+func AddMember(ctx context.Context, courseID, username string, role Role) error {
+	if role != RoleInstructor && role != RoleAssistant {
+		return ErrInvalidRole
+	}
+
+	return pgctx.RunInTx(ctx, func(ctx context.Context) error {
+		var userID string
+		// language=SQL
+		err := pgctx.QueryRow(ctx, `select id from users where username = $1`, username).Scan(&userID)
+		if err == sql.ErrNoRows {
+			return ErrUserNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		r, err := GetRole(ctx, courseID, userID)
+		if err != nil {
+			return err
+		}
+		if r.IsMember() {
+			return ErrAlreadyMember
+		}
+
+		// language=SQL
+		_, err = pgctx.Exec(ctx, `
+			insert into course_members
+				(course_id, user_id, role)
+			values
+				($1, $2, $3)
+		`, courseID, userID, role)
+		return err
+	})
+}
+
+// SetMemberRole changes member's role
+func SetMemberRole(ctx context.Context, courseID, userID string, role Role) error {
+	if role != RoleInstructor && role != RoleAssistant {
+		return ErrInvalidRole
+	}
+
+	// language=SQL
+	res, err := pgctx.Exec(ctx, `
+		update course_members
+		set role = $3
+		where course_id = $1 and user_id = $2
+	`, courseID, userID, role)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+// RemoveMember removes user from course's members
+func RemoveMember(ctx context.Context, courseID, userID string) error {
+	// language=SQL
+	res, err := pgctx.Exec(ctx, `
+		delete from course_members
+		where course_id = $1 and user_id = $2
+	`, courseID, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrMemberNotFound
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package course_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/acoshift/acourse/internal/pkg/course"
+)
+
+var _ = Describe("Member", func() {
+	Describe("Role", func() {
+		Context("Can", func() {
+			It("should allow owner to do everything", func() {
+				Expect(RoleOwner.Can(EditContent)).To(BeTrue())
+				Expect(RoleOwner.Can(Grade)).To(BeTrue())
+				Expect(RoleOwner.Can(ViewRoster)).To(BeTrue())
+				Expect(RoleOwner.Can(Manage)).To(BeTrue())
+			})
+
+			It("should allow instructor to do everything except manage", func() {
+				Expect(RoleInstructor.Can(EditContent)).To(BeTrue())
+				Expect(RoleInstructor.Can(Grade)).To(BeTrue())
+				Expect(RoleInstructor.Can(ViewRoster)).To(BeTrue())
+				Expect(RoleInstructor.Can(Manage)).To(BeFalse())
+			})
+
+			It("should allow assistant to grade and view roster only", func() {
+				Expect(RoleAssistant.Can(EditContent)).To(BeFalse())
+				Expect(RoleAssistant.Can(Grade)).To(BeTrue())
+				Expect(RoleAssistant.Can(ViewRoster)).To(BeTrue())
+				Expect(RoleAssistant.Can(Manage)).To(BeFalse())
+			})
+
+			It("should not allow zero role to do anything", func() {
+				var r Role
+
+				Expect(r.Can(EditContent)).To(BeFalse())
+				Expect(r.Can(Grade)).To(BeFalse())
+				Expect(r.Can(ViewRoster)).To(BeFalse())
+				Expect(r.Can(Manage)).To(BeFalse())
+			})
+
+			It("should not allow unknown permission", func() {
+				Expect(RoleOwner.Can(0)).To(BeFalse())
+			})
+		})
+
+		Context("IsMember", func() {
+			It("should be member for known roles", func() {
+				Expect(RoleOwner.IsMember()).To(BeTrue())
+				Expect(RoleInstructor.IsMember()).To(BeTrue())
+				Expect(RoleAssistant.IsMember()).To(BeTrue())
+			})
+
+			It("should not be member for zero or unknown role", func() {
+				Expect(Role(0).IsMember()).To(BeFalse())
+				Expect(Role(99).IsMember()).To(BeFalse())
+			})
+		})
+	})
+})
@@ -0,0 +1,169 @@
+package course
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/acoshift/pgsql"
+	"github.com/acoshift/pgsql/pgctx"
+	"github.com/satori/go.uuid"
+)
+
+// Prerequisite is a course which user should learn before enroll a course
+type Prerequisite struct {
+	ID       string
+	Title    string
+	Image    string
+	URL      string
+	Required bool // user can not enroll until completed, otherwise user only be warned
+
+	// user's progress in prerequisite
+	Enrolled  bool
+	Completed bool
+}
+
+// Link returns prerequisite course's link
+func (x *Prerequisite) Link() string {
+	if x.URL != "" {
+		return x.URL
+	}
+	return x.ID
+}
+
+// GetPrerequisites gets course's prerequisites with user's progress, empty user id gets no progress,
+// archived prerequisites are closed for enroll so they are ignored
+func GetPrerequisites(ctx context.Context, courseID, userID string) ([]*Prerequisite, error) {
+	// language=SQL
+	rows, err := pgctx.Query(ctx, `
+		select
+			c.id, c.title, c.image, c.url, p.required,
+			e.user_id is not null
+		from course_prerequisites as p
+			inner join courses as c on c.id = p.prerequisite_id
+			left join enrolls as e on e.course_id = c.id and e.user_id = $2
+		where p.course_id = $1 and c.archived_at is null
+		order by p.required desc, p.created_at
+	`, courseID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var xs []*Prerequisite
+	for rows.Next() {
+		var x Prerequisite
+		err = rows.Scan(
+			&x.ID, &x.Title, &x.Image, pgsql.NullString(&x.URL), &x.Required,
+			&x.Enrolled,
+		)
+		if err != nil {
+			return nil, err
+		}
+		xs = append(xs, &x)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	// use the same completion as certificate
+	for _, x := range xs {
+		if !x.Enrolled {
+			continue
+		}
+		x.Completed, err = IsCompleted(ctx, userID, x.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return xs, nil
+}
+
+// AddPrerequisite adds or updates course's prerequisite,
+// link can be prerequisite's id, url or the whole course page's link
+func AddPrerequisite(ctx context.Context, courseID, link string, required bool) error {
+	link = path.Base(strings.TrimSuffix(strings.TrimSpace(link), "/"))
+	if link == "" || link == "." || link == "/" {
+		return ErrNotFound
+	}
+
+	return pgctx.RunInTx(ctx, func(ctx context.Context) error {
+		prerequisiteID, err := getIDByLink(ctx, link)
+		if err != nil {
+			return err
+		}
+		if prerequisiteID == courseID {
+			return ErrInvalidPrerequisite
+		}
+
+		// prerequisite which requires the course will make a cycle
+		var cycle bool
+		// language=SQL
+		err = pgctx.QueryRow(ctx, `
+			with recursive deps as (
+				select prerequisite_id
+				from course_prerequisites
+				where course_id = $1
+				union
+				select p.prerequisite_id
+				from course_prerequisites as p
+					inner join deps as d on d.prerequisite_id = p.course_id
+			)
+			select exists (select 1 from deps where prerequisite_id = $2)
+		`, prerequisiteID, courseID).Scan(&cycle)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return ErrInvalidPrerequisite
+		}
+
+		// language=SQL
+		_, err = pgctx.Exec(ctx, `
+			insert into course_prerequisites
+				(course_id, prerequisite_id, required)
+			values
+				($1, $2, $3)
+			on conflict (course_id, prerequisite_id) do update set
+				required = excluded.required
+		`, courseID, prerequisiteID, required)
+		return err
+	})
+}
+
+// RemovePrerequisite removes course's prerequisite
+func RemovePrerequisite(ctx context.Context, courseID, prerequisiteID string) error {
+	// language=SQL
+	_, err := pgctx.Exec(ctx, `
+		delete from course_prerequisites
+		where course_id = $1 and prerequisite_id = $2
+	`, courseID, prerequisiteID)
+	if pgsql.IsInvalidTextRepresentation(err) {
+		return nil
+	}
+	return err
+}
+
+// getIDByLink gets course id from course's id, url or old url
+func getIDByLink(ctx context.Context, link string) (string, error) {
+	if _, err := uuid.FromString(link); err == nil {
+		var exists bool
+		// language=SQL
+		err = pgctx.QueryRow(ctx, `select exists (select 1 from courses where id = $1)`, link).Scan(&exists)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return "", ErrNotFound
+		}
+		return link, nil
+	}
+
+	id, err := GetIDByURL(ctx, link)
+	if err == ErrNotFound {
+		id, err = GetIDByOldURL(ctx, link)
+	}
+	return id, err
+}
@@ -0,0 +1,200 @@
+package course
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/acoshift/pgsql"
+	"github.com/acoshift/pgsql/pgctx"
+	"github.com/lib/pq"
+
+	"github.com/acoshift/acourse/internal/pkg/email"
+	"github.com/acoshift/acourse/internal/pkg/markdown"
+)
+
+// Content release rules
+const (
+	ReleaseNow    = iota // available right after enroll
+	ReleaseEnroll        // available release days after user's enroll date
+	ReleaseStart         // available release days after course's start date
+)
+
+func validRelease(release, days int) bool {
+	switch release {
+	case ReleaseNow:
+		return true
+	case ReleaseEnroll, ReleaseStart:
+		return days >= 0
+	}
+	return false
+}
+
+// ReleaseAt returns the time when content will be available for user who enrolled at enrolledAt
+// in course which starts at start, zero time means content is available right after enroll
+func (x *Content) ReleaseAt(enrolledAt, start time.Time) time.Time {
+	switch x.Release {
+	case ReleaseEnroll:
+		if !enrolledAt.IsZero() {
+			return enrolledAt.AddDate(0, 0, x.ReleaseDays)
+		}
+	case ReleaseStart:
+		if !start.IsZero() {
+			return start.AddDate(0, 0, x.ReleaseDays)
+		}
+	}
+	return time.Time{}
+}
+
+// Released returns true if content is available for user who enrolled at enrolledAt
+// in course which starts at start
+func (x *Content) Released(enrolledAt, start time.Time) bool {
+	t := x.ReleaseAt(enrolledAt, start)
+	return t.IsZero() || !time.Now().Before(t)
+}
+
+// NotifyReleasedContents emails enrolled users about contents which were released after they enrolled,
+// each user will be notified about each content only once after the email was sent,
+// so failed emails will be retried in the next run
+func NotifyReleasedContents(ctx context.Context) (int, error) {
+	type notification struct {
+		UserID     string
+		Email      string
+		Name       string
+		CourseID   string
+		Course     string
+		CourseURL  string
+		ContentIDs []string
+		Contents   []string
+	}
+
+	// contents released before user enrolled are not new to the user
+	// language=SQL
+	rows, err := pgctx.Query(ctx, `
+		with released as (
+			select
+				cc.id as content_id, e.user_id, e.created_at as enrolled_at,
+				case cc.release
+					when $1 then e.created_at + make_interval(days => cc.release_days)
+					when $2 then c.start + make_interval(days => cc.release_days)
+				end as release_at
+			from course_contents as cc
+				inner join courses as c on c.id = cc.course_id
+				inner join enrolls as e on e.course_id = cc.course_id
+			where cc.release_notify and c.archived_at is null
+		)
+		select
+			u.id, coalesce(u.email, ''), u.name, u.username,
+			c.id, c.title, c.url,
+			cc.id, cc.title
+		from released as r
+			inner join users as u on u.id = r.user_id
+			inner join course_contents as cc on cc.id = r.content_id
+			inner join courses as c on c.id = cc.course_id
+		where r.release_at <= now()
+		  and r.release_at > r.enrolled_at
+		  and not exists (
+			select 1
+			from content_release_notifies as n
+			where n.content_id = r.content_id and n.user_id = r.user_id
+		  )
+		order by u.id, c.id, cc.i
+	`, ReleaseEnroll, ReleaseStart)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var (
+		xs   []*notification
+		last *notification
+	)
+	for rows.Next() {
+		var (
+			x                                 notification
+			username, contentID, contentTitle string
+		)
+		err = rows.Scan(
+			&x.UserID, &x.Email, &x.Name, &username,
+			&x.CourseID, &x.Course, pgsql.NullString(&x.CourseURL),
+			&contentID, &contentTitle,
+		)
+		if err != nil {
+			return 0, err
+		}
+		if x.Name == "" {
+			x.Name = username
+		}
+
+		// group user's released contents by course
+		if last == nil || last.UserID != x.UserID || last.CourseID != x.CourseID {
+			last = &x
+			xs = append(xs, last)
+		}
+		last.ContentIDs = append(last.ContentIDs, contentID)
+		last.Contents = append(last.Contents, contentTitle)
+	}
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+	rows.Close()
+
+	sent := 0
+	for _, x := range xs {
+		// user without email can not be notified, mark as notified to not select again
+		if x.Email != "" {
+			link := x.CourseURL
+			if link == "" {
+				link = x.CourseID
+			}
+
+			var list strings.Builder
+			for _, c := range x.Contents {
+				fmt.Fprintf(&list, "- %s\n", c)
+			}
+
+			body := markdown.Email(fmt.Sprintf(`สวัสดีครับคุณ %s,
+
+
+หลักสูตร "%s" มีบทเรียนใหม่เปิดให้เรียนแล้ว
+
+
+%s
+
+เข้าเรียนได้ที่ https://acourse.io/course/%s/content
+
+----------------------
+
+ทีมงาน acourse.io
+
+https://acourse.io
+`,
+				x.Name,
+				x.Course,
+				list.String(),
+				link,
+			))
+
+			title := fmt.Sprintf("บทเรียนใหม่เปิดให้เรียนแล้ว หลักสูตร %s", x.Course)
+			if email.Send(x.Email, title, body) != nil {
+				// retry in the next run
+				continue
+			}
+			sent++
+		}
+
+		// language=SQL
+		_, err = pgctx.Exec(ctx, `
+			insert into content_release_notifies
+				(content_id, user_id)
+			select unnest($1::uuid[]), $2
+			on conflict do nothing
+		`, pq.Array(x.ContentIDs), x.UserID)
+		if err != nil {
+			return sent, err
+		}
+	}
+
+	return sent, nil
+}
@@ -0,0 +1,109 @@
+package course_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/acoshift/acourse/internal/pkg/course"
+)
+
+var _ = Describe("Release", func() {
+	enrolledAt := time.Date(2020, 1, 31, 10, 0, 0, 0, time.UTC)
+	start := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	Describe("ReleaseAt", func() {
+		Context("ReleaseNow", func() {
+			It("should be zero", func() {
+				x := Content{Release: ReleaseNow, ReleaseDays: 3}
+
+				Expect(x.ReleaseAt(enrolledAt, start)).To(BeZero())
+			})
+		})
+
+		Context("ReleaseEnroll", func() {
+			It("should add release days to enroll date", func() {
+				x := Content{Release: ReleaseEnroll, ReleaseDays: 3}
+
+				Expect(x.ReleaseAt(enrolledAt, start)).To(Equal(time.Date(2020, 2, 3, 10, 0, 0, 0, time.UTC)))
+			})
+
+			It("should be enroll date when release days is zero", func() {
+				x := Content{Release: ReleaseEnroll}
+
+				Expect(x.ReleaseAt(enrolledAt, start)).To(Equal(enrolledAt))
+			})
+
+			It("should be zero when user not enrolled", func() {
+				x := Content{Release: ReleaseEnroll, ReleaseDays: 3}
+
+				Expect(x.ReleaseAt(time.Time{}, start)).To(BeZero())
+			})
+		})
+
+		Context("ReleaseStart", func() {
+			It("should add release days to course's start date", func() {
+				x := Content{Release: ReleaseStart, ReleaseDays: 7}
+
+				Expect(x.ReleaseAt(enrolledAt, start)).To(Equal(time.Date(2020, 3, 8, 0, 0, 0, 0, time.UTC)))
+			})
+
+			It("should be zero when course has no start date", func() {
+				x := Content{Release: ReleaseStart, ReleaseDays: 7}
+
+				Expect(x.ReleaseAt(enrolledAt, time.Time{})).To(BeZero())
+			})
+		})
+	})
+
+	Describe("Released", func() {
+		It("should be released when release now", func() {
+			x := Content{Release: ReleaseNow}
+
+			Expect(x.Released(time.Now(), time.Time{})).To(BeTrue())
+		})
+
+		It("should be released when release after start but course has no start date", func() {
+			x := Content{Release: ReleaseStart, ReleaseDays: 7}
+
+			Expect(x.Released(time.Now(), time.Time{})).To(BeTrue())
+		})
+
+		It("should be released on the enroll date when release days is zero", func() {
+			x := Content{Release: ReleaseEnroll}
+
+			Expect(x.Released(time.Now(), time.Time{})).To(BeTrue())
+		})
+
+		It("should be released exactly release days after enroll", func() {
+			x := Content{Release: ReleaseEnroll, ReleaseDays: 3}
+
+			Expect(x.Released(time.Now().AddDate(0, 0, -3), time.Time{})).To(BeTrue())
+		})
+
+		It("should not be released just before release days after enroll", func() {
+			x := Content{Release: ReleaseEnroll, ReleaseDays: 3}
+
+			Expect(x.Released(time.Now().AddDate(0, 0, -3).Add(time.Minute), time.Time{})).To(BeFalse())
+		})
+
+		It("should be released exactly release days after start", func() {
+			x := Content{Release: ReleaseStart, ReleaseDays: 3}
+
+			Expect(x.Released(time.Now(), time.Now().AddDate(0, 0, -3))).To(BeTrue())
+		})
+
+		It("should not be released just before release days after start", func() {
+			x := Content{Release: ReleaseStart, ReleaseDays: 3}
+
+			Expect(x.Released(time.Now(), time.Now().AddDate(0, 0, -3).Add(time.Minute))).To(BeFalse())
+		})
+
+		It("should not be released before course starts", func() {
+			x := Content{Release: ReleaseStart}
+
+			Expect(x.Released(time.Now(), time.Now().AddDate(0, 0, 1))).To(BeFalse())
+		})
+	})
+})
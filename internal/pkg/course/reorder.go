@@ -0,0 +1,63 @@
+package course
+
+import (
+	"context"
+
+	"github.com/acoshift/pgsql/pgctx"
+)
+
+// reorder sets column i of course's rows in table to match ids,
+// ids must contain every row of the course exactly once
+func reorder(ctx context.Context, table string, courseID string, ids []string, errInvalid error) error {
+	return pgctx.RunInTx(ctx, func(ctx context.Context) error {
+		rows, err := pgctx.Query(ctx, `
+			select id
+			from `+table+`
+			where course_id = $1
+			for update
+		`, courseID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		exists := make(map[string]bool)
+		for rows.Next() {
+			var id string
+			err = rows.Scan(&id)
+			if err != nil {
+				return err
+			}
+			exists[id] = true
+		}
+		if err = rows.Err(); err != nil {
+			return err
+		}
+		rows.Close()
+
+		if len(ids) != len(exists) {
+			return errInvalid
+		}
+		for _, id := range ids {
+			if !exists[id] {
+				return errInvalid
+			}
+			// prevent duplicate id
+			delete(exists, id)
+		}
+
+		for i, id := range ids {
+			_, err = pgctx.Exec(ctx, `
+				update `+table+`
+				set
+					i = $2,
+					updated_at = now()
+				where id = $1
+			`, id, i)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
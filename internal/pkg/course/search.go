@@ -0,0 +1,42 @@
+package course
+
+import (
+	"context"
+
+	"github.com/acoshift/pgsql"
+	"github.com/acoshift/pgsql/pgctx"
+)
+
+// UpdateSearchIndex updates course's search document and text from its title, descriptions and content titles,
+// it must be called after any of them changed
+func UpdateSearchIndex(ctx context.Context, courseID string) error {
+	return updateSearchIndex(ctx, courseID)
+}
+
+// RebuildSearchIndex updates search document and text of all courses
+func RebuildSearchIndex(ctx context.Context) error {
+	return updateSearchIndex(ctx, "")
+}
+
+// updateSearchIndex updates search index of courseID, empty course id updates all courses
+func updateSearchIndex(ctx context.Context, courseID string) error {
+	// language=SQL
+	_, err := pgctx.Exec(ctx, `
+		update courses as c
+		set
+			search_doc = setweight(to_tsvector('simple', c.title), 'A') ||
+				setweight(to_tsvector('simple', c.short_desc), 'B') ||
+				setweight(to_tsvector('simple', ct.titles), 'B') ||
+				setweight(to_tsvector('simple', c.long_desc), 'C'),
+			search_text = lower(c.title || ' ' || c.short_desc || ' ' || ct.titles || ' ' || c.long_desc)
+		from (
+			select cs.id, coalesce(string_agg(cc.title, ' ' order by cc.i), '') as titles
+			from courses as cs
+				left join course_contents as cc on cc.course_id = cs.id
+			where $1::uuid is null or cs.id = $1
+			group by cs.id
+		) as ct
+		where c.id = ct.id
+	`, pgsql.NullString(&courseID))
+	return err
+}
@@ -0,0 +1,216 @@
+package course
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/acoshift/pgsql/pgctx"
+)
+
+// Section groups course's contents into a chapter
+type Section struct {
+	ID       string
+	CourseID string
+	Title    string
+	Desc     string
+	Contents []*Content
+}
+
+type CreateSectionArgs struct {
+	CourseID string
+	Title    string
+	Desc     string
+}
+
+// CreateSection creates new course section
+func CreateSection(ctx context.Context, m *CreateSectionArgs) (string, error) {
+	if m.Title == "" {
+		return "", fmt.Errorf("title required")
+	}
+
+	var sectionID string
+	// language=SQL
+	err := pgctx.QueryRow(ctx, `
+		insert into course_sections
+			(
+				course_id,
+				i,
+				title, long_desc
+			)
+		values
+			(
+				$1,
+				(select coalesce(max(i)+1, 0) from course_sections where course_id = $1),
+				$2, $3
+			)
+		returning id
+	`, m.CourseID, m.Title, m.Desc).Scan(&sectionID)
+	return sectionID, err
+}
+
+type UpdateSectionArgs struct {
+	CourseID  string
+	SectionID string
+	Title     string
+	Desc      string
+}
+
+// UpdateSection updates a course section
+func UpdateSection(ctx context.Context, m *UpdateSectionArgs) error {
+	if m.Title == "" {
+		return fmt.Errorf("title required")
+	}
+
+	// language=SQL
+	res, err := pgctx.Exec(ctx, `
+		update course_sections
+		set
+			title = $3,
+			long_desc = $4,
+			updated_at = now()
+		where id = $1 and course_id = $2
+	`, m.SectionID, m.CourseID, m.Title, m.Desc)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteSection deletes a course section,
+// contents inside the section will be moved out of the section
+func DeleteSection(ctx context.Context, courseID, sectionID string) error {
+	return pgctx.RunInTx(ctx, func(ctx context.Context) error {
+		// language=SQL
+		_, err := pgctx.Exec(ctx, `
+			update course_contents
+			set
+				section_id = null,
+				updated_at = now()
+			where course_id = $1 and section_id = $2
+		`, courseID, sectionID)
+		if err != nil {
+			return err
+		}
+
+		// language=SQL
+		_, err = pgctx.Exec(ctx, `
+			delete from course_sections
+			where id = $1 and course_id = $2
+		`, sectionID, courseID)
+		return err
+	})
+}
+
+// ReorderSections sets the order of course's sections to match sectionIDs,
+// sectionIDs must contain every section of the course exactly once
+func ReorderSections(ctx context.Context, courseID string, sectionIDs []string) error {
+	return reorder(ctx, "course_sections", courseID, sectionIDs, ErrInvalidSections)
+}
+
+// GetSection gets a course's section
+func GetSection(ctx context.Context, courseID, sectionID string) (*Section, error) {
+	var x Section
+	// language=SQL
+	err := pgctx.QueryRow(ctx, `
+		select id, course_id, title, long_desc
+		from course_sections
+		where id = $1 and course_id = $2
+	`, sectionID, courseID).Scan(
+		&x.ID, &x.CourseID, &x.Title, &x.Desc,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &x, nil
+}
+
+// GetSections gets course's sections with their contents,
+// contents without section are grouped into the first section which has empty id
+func GetSections(ctx context.Context, courseID string) ([]*Section, error) {
+	// language=SQL
+	rows, err := pgctx.Query(ctx, `
+		select id, course_id, title, long_desc
+		from course_sections
+		where course_id = $1
+		order by i
+	`, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var xs []*Section
+	for rows.Next() {
+		var x Section
+		err = rows.Scan(&x.ID, &x.CourseID, &x.Title, &x.Desc)
+		if err != nil {
+			return nil, err
+		}
+		xs = append(xs, &x)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	contents, err := GetContents(ctx, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	return GroupContents(xs, contents), nil
+}
+
+// GroupContents groups contents into sections,
+// contents without section are grouped into the first section which has empty id
+func GroupContents(sections []*Section, contents []*Content) []*Section {
+	m := make(map[string]*Section)
+	for _, x := range sections {
+		x.Contents = nil
+		m[x.ID] = x
+	}
+
+	var rest Section
+	for _, x := range contents {
+		s := m[x.SectionID]
+		if s == nil {
+			s = &rest
+		}
+		s.Contents = append(s.Contents, x)
+	}
+
+	if len(rest.Contents) == 0 {
+		return sections
+	}
+	return append([]*Section{&rest}, sections...)
+}
+
+func checkSection(ctx context.Context, courseID, sectionID string) error {
+	if sectionID == "" {
+		return nil
+	}
+
+	var ok bool
+	// language=SQL
+	err := pgctx.QueryRow(ctx, `
+		select exists (
+			select 1
+			from course_sections
+			where id = $1 and course_id = $2
+		)
+	`, sectionID, courseID).Scan(&ok)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidSections
+	}
+	return nil
+}
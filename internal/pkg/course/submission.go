@@ -0,0 +1,291 @@
+package course
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"mime/multipart"
+	"time"
+
+	"github.com/acoshift/pgsql"
+	"github.com/acoshift/pgsql/pgctx"
+
+	"github.com/acoshift/acourse/internal/pkg/email"
+	"github.com/acoshift/acourse/internal/pkg/markdown"
+)
+
+// Submission is a user's answer to an assignment
+type Submission struct {
+	ID           string
+	AssignmentID string
+	Filename     string
+	DownloadURL  string
+	Answer       string
+	Score        int
+	Passed       bool
+	Feedback     string
+	Late         bool
+	CreatedAt    time.Time
+	GradedAt     time.Time
+	User         struct {
+		ID       string
+		Username string
+		Name     string
+		Email    string
+		Image    string
+	}
+}
+
+// Graded returns true if submission was graded by instructor
+func (x *Submission) Graded() bool {
+	return !x.GradedAt.IsZero()
+}
+
+type SubmitAssignmentArgs struct {
+	UserID       string
+	CourseID     string
+	AssignmentID string
+	File         *multipart.FileHeader
+	Answer       string
+}
+
+// SubmitAssignment uploads user's file to an open assignment,
+// user can submit many times, every submission is kept as history
+func SubmitAssignment(ctx context.Context, m *SubmitAssignmentArgs) (string, error) {
+	if err := checkAttachment(m.File); err != nil {
+		return "", err
+	}
+
+	x, err := GetAssignment(ctx, m.CourseID, m.AssignmentID)
+	if err != nil {
+		return "", err
+	}
+
+	enrolledAt, err := GetEnrolledAt(ctx, m.UserID, m.CourseID)
+	if err != nil {
+		return "", err
+	}
+	if !x.Accepting(enrolledAt) {
+		return "", ErrAssignmentClosed
+	}
+
+	name, downloadURL, err := storeAttachment(ctx, m.File)
+	if err != nil {
+		return "", err
+	}
+
+	var id string
+	// language=SQL
+	err = pgctx.QueryRow(ctx, `
+		insert into user_assignments
+			(user_id, assignment_id, filename, download_url, answer, late)
+		values
+			($1, $2, $3, $4, $5, $6)
+		returning id
+	`, m.UserID, m.AssignmentID, name, downloadURL, m.Answer, x.IsLate(enrolledAt)).Scan(&id)
+	return id, err
+}
+
+type GradeSubmissionArgs struct {
+	CourseID     string
+	SubmissionID string
+	Score        int
+	Passed       bool
+	Feedback     string
+}
+
+// GradeSubmission sets score and feedback to a submission,
+// then notify the submitter via email
+func GradeSubmission(ctx context.Context, m *GradeSubmissionArgs) error {
+	// language=SQL
+	res, err := pgctx.Exec(ctx, `
+		update user_assignments
+		set
+			score = $3,
+			passed = $4,
+			feedback = $5,
+			graded_at = now()
+		where id = $1
+		  and assignment_id in (select id from assignments where course_id = $2)
+	`, m.SubmissionID, m.CourseID, m.Score, m.Passed, m.Feedback)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+
+	go func() {
+		x, err := GetSubmission(ctx, m.SubmissionID)
+		if err != nil {
+			return
+		}
+
+		var assignmentTitle, courseTitle string
+		// language=SQL
+		err = pgctx.QueryRow(ctx, `
+			select a.title, c.title
+			from assignments a
+				left join courses c on a.course_id = c.id
+			where a.id = $1
+		`, x.AssignmentID).Scan(&assignmentTitle, &courseTitle)
+		if err != nil {
+			return
+		}
+
+		name := x.User.Name
+		if len(name) == 0 {
+			name = x.User.Username
+		}
+		result := "ไม่ผ่าน"
+		if x.Passed {
+			result = "ผ่าน"
+		}
+		body := markdown.Email(fmt.Sprintf(`สวัสดีครับคุณ %s,
+
+
+การบ้าน "%s" ของหลักสูตร "%s" ได้รับการตรวจแล้ว
+
+
+คะแนน: %d
+
+ผลการตรวจ: %s
+
+----------------------
+
+%s
+
+----------------------
+
+ทีมงาน acourse.io
+
+https://acourse.io
+`,
+			name,
+			assignmentTitle,
+			courseTitle,
+			x.Score,
+			result,
+			x.Feedback,
+		))
+
+		title := fmt.Sprintf("ผลการตรวจการบ้าน %s หลักสูตร %s", assignmentTitle, courseTitle)
+		email.Send(x.User.Email, title, body)
+	}()
+
+	return nil
+}
+
+// GetSubmission gets a submission
+func GetSubmission(ctx context.Context, submissionID string) (*Submission, error) {
+	var x Submission
+	// language=SQL
+	err := pgctx.QueryRow(ctx, `
+		select
+			s.id, s.assignment_id,
+			s.filename, s.download_url, s.answer,
+			s.score, s.passed, s.feedback, s.late,
+			s.created_at, s.graded_at,
+			u.id, u.username, u.name, coalesce(u.email, ''), u.image
+		from user_assignments s
+			left join users u on s.user_id = u.id
+		where s.id = $1
+	`, submissionID).Scan(
+		&x.ID, &x.AssignmentID,
+		&x.Filename, &x.DownloadURL, &x.Answer,
+		&x.Score, &x.Passed, &x.Feedback, &x.Late,
+		&x.CreatedAt, pgsql.NullTime(&x.GradedAt),
+		&x.User.ID, &x.User.Username, &x.User.Name, &x.User.Email, &x.User.Image,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &x, nil
+}
+
+// GetSubmissions gets all users' submissions of an assignment sorted by newest first
+func GetSubmissions(ctx context.Context, assignmentID string) ([]*Submission, error) {
+	// language=SQL
+	rows, err := pgctx.Query(ctx, `
+		select
+			s.id, s.assignment_id,
+			s.filename, s.download_url, s.answer,
+			s.score, s.passed, s.feedback, s.late,
+			s.created_at, s.graded_at,
+			u.id, u.username, u.name, coalesce(u.email, ''), u.image
+		from user_assignments s
+			left join users u on s.user_id = u.id
+		where s.assignment_id = $1
+		order by s.created_at desc
+	`, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var xs []*Submission
+	for rows.Next() {
+		var x Submission
+		err = rows.Scan(
+			&x.ID, &x.AssignmentID,
+			&x.Filename, &x.DownloadURL, &x.Answer,
+			&x.Score, &x.Passed, &x.Feedback, &x.Late,
+			&x.CreatedAt, pgsql.NullTime(&x.GradedAt),
+			&x.User.ID, &x.User.Username, &x.User.Name, &x.User.Email, &x.User.Image,
+		)
+		if err != nil {
+			return nil, err
+		}
+		xs = append(xs, &x)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return xs, nil
+}
+
+// GetUserSubmissions gets user's submissions of course's assignments,
+// grouped by assignment id and sorted by newest first
+func GetUserSubmissions(ctx context.Context, userID, courseID string) (map[string][]*Submission, error) {
+	// language=SQL
+	rows, err := pgctx.Query(ctx, `
+		select
+			s.id, s.assignment_id,
+			s.filename, s.download_url, s.answer,
+			s.score, s.passed, s.feedback, s.late,
+			s.created_at, s.graded_at,
+			u.id, u.username, u.name, coalesce(u.email, ''), u.image
+		from user_assignments s
+			left join users u on s.user_id = u.id
+		where s.user_id = $1
+		  and s.assignment_id in (select id from assignments where course_id = $2)
+		order by s.created_at desc
+	`, userID, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	xs := make(map[string][]*Submission)
+	for rows.Next() {
+		var x Submission
+		err = rows.Scan(
+			&x.ID, &x.AssignmentID,
+			&x.Filename, &x.DownloadURL, &x.Answer,
+			&x.Score, &x.Passed, &x.Feedback, &x.Late,
+			&x.CreatedAt, pgsql.NullTime(&x.GradedAt),
+			&x.User.ID, &x.User.Username, &x.User.Name, &x.User.Email, &x.User.Image,
+		)
+		if err != nil {
+			return nil, err
+		}
+		xs[x.AssignmentID] = append(xs[x.AssignmentID], &x)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return xs, nil
+}
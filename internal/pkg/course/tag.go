@@ -0,0 +1,72 @@
+package course
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/acoshift/pgsql/pgctx"
+	"github.com/lib/pq"
+)
+
+// Tag limits
+const (
+	MaxTags      = 10
+	MaxTagLength = 32
+)
+
+// ParseTags splits comma separated tags into lower case tags without duplicates
+func ParseTags(s string) []string {
+	var xs []string
+	seen := make(map[string]bool)
+	for _, t := range strings.Split(s, ",") {
+		t = strings.Join(strings.Fields(strings.ToLower(t)), " ")
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		xs = append(xs, t)
+	}
+	return xs
+}
+
+// ValidTags checks is tags can be set to course
+func ValidTags(tags []string) bool {
+	if len(tags) > MaxTags {
+		return false
+	}
+	for _, t := range tags {
+		if t == "" || utf8.RuneCountInString(t) > MaxTagLength {
+			return false
+		}
+	}
+	return true
+}
+
+// SetTags replaces course tags
+func SetTags(ctx context.Context, courseID string, tags []string) error {
+	if !ValidTags(tags) {
+		return ErrInvalidTags
+	}
+
+	return pgctx.RunInTx(ctx, func(ctx context.Context) error {
+		// language=SQL
+		_, err := pgctx.Exec(ctx, `delete from course_tags where course_id = $1`, courseID)
+		if err != nil {
+			return err
+		}
+
+		if len(tags) == 0 {
+			return nil
+		}
+
+		// language=SQL
+		_, err = pgctx.Exec(ctx, `
+			insert into course_tags
+				(course_id, tag)
+			select $1, unnest($2::varchar[])
+			on conflict do nothing
+		`, courseID, pq.Array(tags))
+		return err
+	})
+}
@@ -0,0 +1,124 @@
+package course
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+
+	"github.com/acoshift/pgsql"
+	"github.com/acoshift/pgsql/pgctx"
+	"github.com/satori/go.uuid"
+)
+
+var reURL = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// ValidURL checks is url can be used as course's url
+func ValidURL(url string) bool {
+	if len(url) < 3 || len(url) > 64 || !reURL.MatchString(url) {
+		return false
+	}
+
+	// course link which is uuid will be treated as course id
+	_, err := uuid.FromString(url)
+	return err != nil
+}
+
+// SetURL sets course url, empty url removes course url,
+// old url will be kept in history to redirect to the new one
+func SetURL(ctx context.Context, courseID, url string) error {
+	if url != "" && !ValidURL(url) {
+		return ErrInvalidURL
+	}
+
+	return pgctx.RunInTx(ctx, func(ctx context.Context) error {
+		var old string
+		// language=SQL
+		err := pgctx.QueryRow(ctx, `
+			select url
+			from courses
+			where id = $1
+			for update
+		`, courseID).Scan(pgsql.NullString(&old))
+		if err == sql.ErrNoRows {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if old == url {
+			return nil
+		}
+
+		if old != "" {
+			// language=SQL
+			_, err = pgctx.Exec(ctx, `
+				insert into course_url_history
+					(url, course_id)
+				values
+					($1, $2)
+				on conflict (url) do update set
+					course_id = excluded.course_id,
+					created_at = now()
+			`, old, courseID)
+			if err != nil {
+				return err
+			}
+		}
+
+		if url != "" {
+			// url still redirects to another course
+			var used bool
+			// language=SQL
+			err = pgctx.QueryRow(ctx, `
+				select exists (
+					select 1
+					from course_url_history
+					where url = $1 and course_id != $2
+				)
+			`, url, courseID).Scan(&used)
+			if err != nil {
+				return err
+			}
+			if used {
+				return ErrURLNotAvailable
+			}
+
+			// course takes its old url back
+			// language=SQL
+			_, err = pgctx.Exec(ctx, `
+				delete from course_url_history
+				where url = $1 and course_id = $2
+			`, url, courseID)
+			if err != nil {
+				return err
+			}
+		}
+
+		// language=SQL
+		_, err = pgctx.Exec(ctx, `
+			update courses
+			set
+				url = $2,
+				updated_at = now()
+			where id = $1
+		`, courseID, pgsql.NullString(&url))
+		if pgsql.IsUniqueViolation(err, "courses_url_idx") {
+			return ErrURLNotAvailable
+		}
+		return err
+	})
+}
+
+// GetIDByOldURL gets course id from course's old url
+func GetIDByOldURL(ctx context.Context, url string) (courseID string, err error) {
+	// language=SQL
+	err = pgctx.QueryRow(ctx, `
+		select course_id
+		from course_url_history
+		where url = $1
+	`, url).Scan(&courseID)
+	if err == sql.ErrNoRows {
+		err = ErrNotFound
+	}
+	return
+}
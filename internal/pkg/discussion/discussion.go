@@ -0,0 +1,274 @@
+package discussion
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/acoshift/pgsql"
+	"github.com/acoshift/pgsql/pgctx"
+
+	"github.com/acoshift/acourse/internal/pkg/email"
+	"github.com/acoshift/acourse/internal/pkg/markdown"
+)
+
+// Comment is a comment on course's content, comment without parent is a question
+// and replies are comments which have the question as parent
+type Comment struct {
+	ID        string
+	ContentID string
+	ParentID  string
+	User      struct {
+		ID       string
+		Username string
+		Name     string
+		Image    string
+	}
+	Body       string // markdown
+	Instructor bool   // comment from course owner or member
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	Replies    []*Comment
+}
+
+// Edited returns true if comment was edited after created
+func (x *Comment) Edited() bool {
+	return x.UpdatedAt.Sub(x.CreatedAt) > time.Second
+}
+
+// List lists content's questions, newest first, with their replies, oldest first
+func List(ctx context.Context, contentID string) ([]*Comment, error) {
+	// language=SQL
+	rows, err := pgctx.Query(ctx, `
+		select
+			m.id, m.content_id, m.parent_id, m.body,
+			m.created_at, m.updated_at,
+			u.id, u.username, u.name, u.image,
+			u.id = c.user_id or exists (
+				select 1 from course_members where course_id = c.id and user_id = u.id
+			)
+		from content_comments as m
+			left join users as u on u.id = m.user_id
+			left join course_contents as cc on cc.id = m.content_id
+			left join courses as c on c.id = cc.course_id
+		where m.content_id = $1
+		order by m.created_at
+	`, contentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var xs []*Comment
+	threads := make(map[string]*Comment)
+	for rows.Next() {
+		var x Comment
+		err = rows.Scan(
+			&x.ID, &x.ContentID, pgsql.NullString(&x.ParentID), &x.Body,
+			&x.CreatedAt, &x.UpdatedAt,
+			&x.User.ID, &x.User.Username, &x.User.Name, &x.User.Image,
+			&x.Instructor,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if x.ParentID == "" {
+			threads[x.ID] = &x
+			xs = append([]*Comment{&x}, xs...)
+			continue
+		}
+		if p := threads[x.ParentID]; p != nil {
+			p.Replies = append(p.Replies, &x)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return xs, nil
+}
+
+type CreateArgs struct {
+	ContentID string
+	UserID    string
+	ParentID  string // empty for new question
+	Body      string
+}
+
+// Create creates new comment, reply to a reply is added to the reply's question,
+// new question from student is notified to course owner via email
+func Create(ctx context.Context, m *CreateArgs) (string, error) {
+	if m.Body == "" {
+		return "", ErrInvalidBody
+	}
+
+	parentID := m.ParentID
+	if parentID != "" {
+		var contentID, rootID string
+		// language=SQL
+		err := pgctx.QueryRow(ctx, `
+			select content_id, parent_id
+			from content_comments
+			where id = $1
+		`, parentID).Scan(&contentID, pgsql.NullString(&rootID))
+		if err == sql.ErrNoRows || pgsql.IsInvalidTextRepresentation(err) {
+			return "", ErrNotFound
+		}
+		if err != nil {
+			return "", err
+		}
+		if contentID != m.ContentID {
+			return "", ErrNotFound
+		}
+		if rootID != "" {
+			parentID = rootID
+		}
+	}
+
+	var id string
+	// language=SQL
+	err := pgctx.QueryRow(ctx, `
+		insert into content_comments
+			(content_id, user_id, parent_id, body)
+		values
+			($1, $2, $3, $4)
+		returning id
+	`, m.ContentID, m.UserID, pgsql.NullString(&parentID), m.Body).Scan(&id)
+	if err != nil {
+		return "", err
+	}
+
+	if parentID == "" {
+		go notifyOwner(ctx, id)
+	}
+
+	return id, nil
+}
+
+// notifyOwner sends new question to course owner via email
+func notifyOwner(ctx context.Context, commentID string) {
+	var (
+		ownerID, ownerName, ownerUsername, ownerEmail string
+		userID, userName, userUsername                string
+		courseTitle, contentTitle, body               string
+	)
+	// language=SQL
+	err := pgctx.QueryRow(ctx, `
+		select
+			o.id, o.name, o.username, coalesce(o.email, ''),
+			u.id, u.name, u.username,
+			c.title, cc.title, m.body
+		from content_comments as m
+			left join course_contents as cc on cc.id = m.content_id
+			left join courses as c on c.id = cc.course_id
+			left join users as o on o.id = c.user_id
+			left join users as u on u.id = m.user_id
+		where m.id = $1
+	`, commentID).Scan(
+		&ownerID, &ownerName, &ownerUsername, &ownerEmail,
+		&userID, &userName, &userUsername,
+		&courseTitle, &contentTitle, &body,
+	)
+	if err != nil {
+		return
+	}
+
+	// owner's own question does not need to notify
+	if ownerID == userID || ownerEmail == "" {
+		return
+	}
+
+	if ownerName == "" {
+		ownerName = ownerUsername
+	}
+	if userName == "" {
+		userName = userUsername
+	}
+	html := markdown.Email(fmt.Sprintf(`สวัสดีครับคุณ %s,
+
+
+คุณ %s ได้ถามคำถามในบทเรียน "%s" ของหลักสูตร "%s"
+
+
+----------------------
+
+%s
+
+----------------------
+
+ทีมงาน acourse.io
+
+https://acourse.io
+`,
+		ownerName,
+		userName,
+		contentTitle,
+		courseTitle,
+		body,
+	))
+
+	title := fmt.Sprintf("คำถามใหม่ในบทเรียน %s หลักสูตร %s", contentTitle, courseTitle)
+	email.Send(ownerEmail, title, html)
+}
+
+// Update updates user's own comment
+func Update(ctx context.Context, commentID, userID, body string) error {
+	if body == "" {
+		return ErrInvalidBody
+	}
+
+	// language=SQL
+	res, err := pgctx.Exec(ctx, `
+		update content_comments
+		set
+			body = $3,
+			updated_at = now()
+		where id = $1 and user_id = $2
+	`, commentID, userID, body)
+	if pgsql.IsInvalidTextRepresentation(err) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Delete deletes user's own comment, deleting a question also deletes its replies
+func Delete(ctx context.Context, commentID, userID string) error {
+	return pgctx.RunInTx(ctx, func(ctx context.Context) error {
+		var ok bool
+		// language=SQL
+		err := pgctx.QueryRow(ctx, `
+			select exists (
+				select 1
+				from content_comments
+				where id = $1 and user_id = $2
+			)
+		`, commentID, userID).Scan(&ok)
+		if pgsql.IsInvalidTextRepresentation(err) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrNotFound
+		}
+
+		// language=SQL
+		_, err = pgctx.Exec(ctx, `delete from content_comments where parent_id = $1`, commentID)
+		if err != nil {
+			return err
+		}
+
+		// language=SQL
+		_, err = pgctx.Exec(ctx, `delete from content_comments where id = $1`, commentID)
+		return err
+	})
+}
@@ -0,0 +1,10 @@
+package discussion
+
+import (
+	"errors"
+)
+
+var (
+	ErrNotFound    = errors.New("discussion: not found")
+	ErrInvalidBody = errors.New("discussion: invalid body")
+)
@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"mime"
+	"regexp"
+	"strings"
 
 	"cloud.google.com/go/storage"
 	uuid "github.com/satori/go.uuid"
@@ -14,11 +17,15 @@ import (
 var (
 	bucketName string
 	bucket     *storage.BucketHandle
+	reStoreURL *regexp.Regexp
 )
 
 func Init() {
 	bucketName = config.String("bucket")
 	bucket = config.StorageClient().Bucket(bucketName)
+	if bucketName != "" {
+		reStoreURL = regexp.MustCompile(regexp.QuoteMeta(downloadURL("")) + `[^\s()<>"'\[\]]+`)
+	}
 }
 
 // GenerateFilename generates new filename
@@ -28,6 +35,15 @@ func GenerateFilename() string {
 
 // Store stores file
 func Store(ctx context.Context, r io.Reader, filename string, async bool) (string, error) {
+	return store(ctx, r, filename, "", async)
+}
+
+// StoreAttachment stores file which will be downloaded as name
+func StoreAttachment(ctx context.Context, r io.Reader, filename string, name string) (string, error) {
+	return store(ctx, r, filename, mime.FormatMediaType("attachment", map[string]string{"filename": name}), false)
+}
+
+func store(ctx context.Context, r io.Reader, filename string, disposition string, async bool) (string, error) {
 	if len(filename) == 0 {
 		return "", fmt.Errorf("invalid filename")
 	}
@@ -40,6 +56,7 @@ func Store(ctx context.Context, r io.Reader, filename string, async bool) (strin
 		defer w.Close()
 
 		w.CacheControl = "max-age=31536000, immutable"
+		w.ContentDisposition = disposition
 
 		_, err := io.Copy(w, r)
 		return err
@@ -56,3 +73,16 @@ func Store(ctx context.Context, r io.Reader, filename string, async bool) (strin
 func downloadURL(filename string) string {
 	return fmt.Sprintf("https://storage.googleapis.com/%s/%s", bucketName, filename)
 }
+
+// IsStoreURL checks is url stored by Store
+func IsStoreURL(url string) bool {
+	return bucketName != "" && strings.HasPrefix(url, downloadURL(""))
+}
+
+// FindStoreURLs finds urls stored by Store in text, e.g. images in markdown
+func FindStoreURLs(s string) []string {
+	if reStoreURL == nil {
+		return nil
+	}
+	return reStoreURL.FindAllString(s, -1)
+}
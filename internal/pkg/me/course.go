@@ -35,6 +35,7 @@ func (x *OwnCourse) ShowStart() bool {
 	return x.Type == course.Live && !x.Start.IsZero()
 }
 
+// GetOwnCourses gets courses which user owns or teaches as course's member
 func GetOwnCourses(ctx context.Context, userID string) ([]*OwnCourse, error) {
 	// language=SQL
 	rows, err := pgctx.Query(ctx, `
@@ -46,6 +47,7 @@ func GetOwnCourses(ctx context.Context, userID string) ([]*OwnCourse, error) {
 		from courses as c
 			left join enrolls as e on e.course_id = c.id
 		where c.user_id = $1
+		   or exists (select 1 from course_members where course_id = c.id and user_id = $1)
 		group by c.id, c.*
 		order by c.created_at desc
 	`, userID)
@@ -77,13 +79,15 @@ func GetOwnCourses(ctx context.Context, userID string) ([]*OwnCourse, error) {
 
 // EnrolledCourse type
 type EnrolledCourse struct {
-	ID    string
-	Title string
-	Desc  string
-	Image string
-	Start time.Time
-	URL   string
-	Type  int
+	ID             string
+	Title          string
+	Desc           string
+	Image          string
+	Start          time.Time
+	URL            string
+	Type           int
+	ContentCount   int
+	CompletedCount int
 }
 
 // Link returns course link
@@ -99,13 +103,23 @@ func (x *EnrolledCourse) ShowStart() bool {
 	return x.Type == course.Live && !x.Start.IsZero()
 }
 
+// Progress returns completed contents in percent
+func (x *EnrolledCourse) Progress() int {
+	if x.ContentCount == 0 {
+		return 0
+	}
+	return x.CompletedCount * 100 / x.ContentCount
+}
+
 func GetEnrolledCourses(ctx context.Context, userID string) ([]*EnrolledCourse, error) {
 	// language=SQL
 	rows, err := pgctx.Query(ctx, `
 		select
 			c.id,
 			c.title, c.short_desc, c.image,
-			c.start, c.url, c.type
+			c.start, c.url, c.type,
+			(select count(*) from course_contents where course_id = c.id),
+			(select count(*) from progresses where user_id = $1 and course_id = c.id and completed_at is not null)
 		from courses as c
 			inner join enrolls as e on c.id = e.course_id
 		where e.user_id = $1
@@ -123,6 +137,7 @@ func GetEnrolledCourses(ctx context.Context, userID string) ([]*EnrolledCourse,
 			&x.ID,
 			&x.Title, &x.Desc, &x.Image,
 			pgsql.NullTime(&x.Start), pgsql.NullString(&x.URL), &x.Type,
+			&x.ContentCount, &x.CompletedCount,
 		)
 		if err != nil {
 			return nil, err
@@ -19,7 +19,8 @@ import (
 )
 
 var (
-	ErrImageRequired = errors.New("me: image required")
+	ErrImageRequired        = errors.New("me: image required")
+	ErrPrerequisiteRequired = errors.New("me: prerequisite required")
 )
 
 // Enroll enrolls a course
@@ -58,6 +59,19 @@ func Enroll(ctx context.Context, courseID string, price float64, paymentImage *m
 		}
 	}
 
+	// has not completed required prerequisites
+	{
+		prerequisites, err := course.GetPrerequisites(ctx, courseID, userID)
+		if err != nil {
+			return err
+		}
+		for _, x := range prerequisites {
+			if x.Required && !x.Completed {
+				return ErrPrerequisiteRequired
+			}
+		}
+	}
+
 	originalPrice := c.Price
 	if c.Option.Discount {
 		originalPrice = c.Discount
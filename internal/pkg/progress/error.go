@@ -0,0 +1,9 @@
+package progress
+
+import (
+	"errors"
+)
+
+var (
+	ErrNotFound = errors.New("progress: not found")
+)
@@ -0,0 +1,84 @@
+package progress
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/acoshift/pgsql/pgctx"
+)
+
+// View records that user viewed a course's content
+func View(ctx context.Context, userID, courseID, contentID string) error {
+	// language=SQL
+	_, err := pgctx.Exec(ctx, `
+		insert into progresses
+			(user_id, course_id, content_id)
+		values
+			($1, $2, $3)
+		on conflict (user_id, content_id) do update set
+			viewed_at = now()
+	`, userID, courseID, contentID)
+	return err
+}
+
+// SetCompleted marks a course's content as completed or not completed
+func SetCompleted(ctx context.Context, userID, courseID, contentID string, completed bool) error {
+	// language=SQL
+	_, err := pgctx.Exec(ctx, `
+		insert into progresses
+			(user_id, course_id, content_id, completed_at)
+		values
+			($1, $2, $3, case when $4 then now() end)
+		on conflict (user_id, content_id) do update set
+			completed_at = case
+				when not $4 then null
+				else coalesce(progresses.completed_at, now())
+			end,
+			viewed_at = now()
+	`, userID, courseID, contentID, completed)
+	return err
+}
+
+// GetLastViewed gets content id which user last viewed in a course
+func GetLastViewed(ctx context.Context, userID, courseID string) (contentID string, err error) {
+	// language=SQL
+	err = pgctx.QueryRow(ctx, `
+		select content_id
+		from progresses
+		where user_id = $1 and course_id = $2
+		order by viewed_at desc
+		limit 1
+	`, userID, courseID).Scan(&contentID)
+	if err == sql.ErrNoRows {
+		err = ErrNotFound
+	}
+	return
+}
+
+// GetCompleted gets set of content ids which user completed in a course
+func GetCompleted(ctx context.Context, userID, courseID string) (map[string]bool, error) {
+	// language=SQL
+	rows, err := pgctx.Query(ctx, `
+		select content_id
+		from progresses
+		where user_id = $1 and course_id = $2 and completed_at is not null
+	`, userID, courseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	xs := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		xs[id] = true
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return xs, nil
+}
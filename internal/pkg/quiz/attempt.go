@@ -0,0 +1,103 @@
+package quiz
+
+import (
+	"context"
+	"time"
+
+	"github.com/acoshift/pgsql"
+	"github.com/acoshift/pgsql/pgctx"
+)
+
+// Attempt is user's scored answers of a quiz
+type Attempt struct {
+	ID        string
+	UserID    string
+	QuizID    string
+	Answers   map[string][]string
+	Score     int
+	Total     int
+	Passed    bool
+	CreatedAt time.Time
+}
+
+// Submit scores user's answers and records as new attempt
+func Submit(ctx context.Context, userID string, x *Quiz, answers map[string][]string) (*Attempt, error) {
+	score, total := x.Score(answers)
+	a := Attempt{
+		UserID:  userID,
+		QuizID:  x.ID,
+		Answers: answers,
+		Score:   score,
+		Total:   total,
+		Passed:  x.Passed(score, total),
+	}
+
+	err := pgctx.RunInTx(ctx, func(ctx context.Context) error {
+		// lock quiz to prevent user submits over attempt limit
+		// language=SQL
+		_, err := pgctx.Exec(ctx, `select 1 from quizzes where id = $1 for update`, x.ID)
+		if err != nil {
+			return err
+		}
+
+		var cnt int
+		// language=SQL
+		err = pgctx.QueryRow(ctx, `
+			select count(*)
+			from quiz_attempts
+			where user_id = $1 and quiz_id = $2
+		`, userID, x.ID).Scan(&cnt)
+		if err != nil {
+			return err
+		}
+		if !x.CanAttempt(cnt) {
+			return ErrNoAttemptsLeft
+		}
+
+		// language=SQL
+		return pgctx.QueryRow(ctx, `
+			insert into quiz_attempts
+				(user_id, quiz_id, answers, score, total, passed)
+			values
+				($1, $2, $3, $4, $5, $6)
+			returning id, created_at
+		`,
+			a.UserID, a.QuizID, pgsql.JSON(a.Answers), a.Score, a.Total, a.Passed,
+		).Scan(&a.ID, &a.CreatedAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// GetAttempts gets user's attempts of a quiz sorted by newest first
+func GetAttempts(ctx context.Context, userID, quizID string) ([]*Attempt, error) {
+	// language=SQL
+	rows, err := pgctx.Query(ctx, `
+		select id, user_id, quiz_id, answers, score, total, passed, created_at
+		from quiz_attempts
+		where user_id = $1 and quiz_id = $2
+		order by created_at desc
+	`, userID, quizID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var xs []*Attempt
+	for rows.Next() {
+		var x Attempt
+		err = rows.Scan(
+			&x.ID, &x.UserID, &x.QuizID, pgsql.JSON(&x.Answers), &x.Score, &x.Total, &x.Passed, &x.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		xs = append(xs, &x)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return xs, nil
+}
@@ -0,0 +1,12 @@
+package quiz
+
+import (
+	"errors"
+)
+
+var (
+	ErrNotFound        = errors.New("quiz: not found")
+	ErrInvalidQuiz     = errors.New("quiz: invalid quiz")
+	ErrInvalidQuestion = errors.New("quiz: invalid question")
+	ErrNoAttemptsLeft  = errors.New("quiz: no attempts left")
+)
@@ -0,0 +1,219 @@
+package quiz
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/acoshift/pgsql/pgctx"
+	"github.com/lib/pq"
+)
+
+// Question types
+const (
+	SingleChoice = iota + 1
+	MultipleChoice
+	Text
+)
+
+// Text answer match modes
+const (
+	Exact      = iota // whole answer, case sensitive
+	Regex             // whole answer matches regular expression
+	IgnoreCase        // whole answer, case insensitive
+)
+
+// Quiz is a set of questions attached to a course's content
+type Quiz struct {
+	ID          string
+	ContentID   string
+	Title       string
+	PassMark    int // percent of correct answers to pass the quiz
+	MaxAttempts int // 0 is unlimited
+	Questions   []*Question
+}
+
+// Question model
+type Question struct {
+	ID       string
+	QuizID   string
+	Type     int
+	Question string
+	Choices  []string
+	Corrects []int64  // indexes of correct choices
+	Answers  []string // accepted text answers
+	Match    int
+}
+
+type SaveArgs struct {
+	ContentID   string
+	Title       string
+	PassMark    int
+	MaxAttempts int
+}
+
+// Save creates or updates content's quiz
+func Save(ctx context.Context, m *SaveArgs) (string, error) {
+	if m.PassMark < 0 || m.PassMark > 100 || m.MaxAttempts < 0 {
+		return "", ErrInvalidQuiz
+	}
+
+	var id string
+	// language=SQL
+	err := pgctx.QueryRow(ctx, `
+		insert into quizzes
+			(content_id, title, pass_mark, max_attempts)
+		values
+			($1, $2, $3, $4)
+		on conflict (content_id) do update set
+			title = excluded.title,
+			pass_mark = excluded.pass_mark,
+			max_attempts = excluded.max_attempts,
+			updated_at = now()
+		returning id
+	`, m.ContentID, m.Title, m.PassMark, m.MaxAttempts).Scan(&id)
+	return id, err
+}
+
+// Delete deletes content's quiz with its questions and attempts
+func Delete(ctx context.Context, contentID string) error {
+	return pgctx.RunInTx(ctx, func(ctx context.Context) error {
+		// language=SQL
+		_, err := pgctx.Exec(ctx, `
+			delete from quiz_attempts
+			where quiz_id in (select id from quizzes where content_id = $1)
+		`, contentID)
+		if err != nil {
+			return err
+		}
+
+		// language=SQL
+		_, err = pgctx.Exec(ctx, `
+			delete from quiz_questions
+			where quiz_id in (select id from quizzes where content_id = $1)
+		`, contentID)
+		if err != nil {
+			return err
+		}
+
+		// language=SQL
+		_, err = pgctx.Exec(ctx, `delete from quizzes where content_id = $1`, contentID)
+		return err
+	})
+}
+
+// Get gets content's quiz with its questions
+func Get(ctx context.Context, contentID string) (*Quiz, error) {
+	var x Quiz
+	// language=SQL
+	err := pgctx.QueryRow(ctx, `
+		select id, content_id, title, pass_mark, max_attempts
+		from quizzes
+		where content_id = $1
+	`, contentID).Scan(
+		&x.ID, &x.ContentID, &x.Title, &x.PassMark, &x.MaxAttempts,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// language=SQL
+	rows, err := pgctx.Query(ctx, `
+		select id, quiz_id, type, question, choices, corrects, answers, match
+		from quiz_questions
+		where quiz_id = $1
+		order by i
+	`, x.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var q Question
+		err = rows.Scan(
+			&q.ID, &q.QuizID, &q.Type, &q.Question,
+			pq.Array(&q.Choices), pq.Array(&q.Corrects), pq.Array(&q.Answers), &q.Match,
+		)
+		if err != nil {
+			return nil, err
+		}
+		x.Questions = append(x.Questions, &q)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return &x, nil
+}
+
+// AddQuestion adds new question to the end of the quiz
+func AddQuestion(ctx context.Context, q *Question) (string, error) {
+	if err := q.Validate(); err != nil {
+		return "", err
+	}
+
+	var id string
+	// language=SQL
+	err := pgctx.QueryRow(ctx, `
+		insert into quiz_questions
+			(
+				quiz_id,
+				i,
+				type, question, choices, corrects, answers, match
+			)
+		values
+			(
+				$1,
+				(select coalesce(max(i)+1, 0) from quiz_questions where quiz_id = $1),
+				$2, $3, $4, $5, $6, $7
+			)
+		returning id
+	`,
+		q.QuizID,
+		q.Type, q.Question, pq.Array(q.Choices), pq.Array(q.Corrects), pq.Array(q.Answers), q.Match,
+	).Scan(&id)
+	return id, err
+}
+
+// UpdateQuestion updates a quiz's question
+func UpdateQuestion(ctx context.Context, q *Question) error {
+	if err := q.Validate(); err != nil {
+		return err
+	}
+
+	// language=SQL
+	res, err := pgctx.Exec(ctx, `
+		update quiz_questions
+		set
+			type = $3,
+			question = $4,
+			choices = $5,
+			corrects = $6,
+			answers = $7,
+			match = $8,
+			updated_at = now()
+		where id = $1 and quiz_id = $2
+	`,
+		q.ID, q.QuizID,
+		q.Type, q.Question, pq.Array(q.Choices), pq.Array(q.Corrects), pq.Array(q.Answers), q.Match,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteQuestion deletes a quiz's question
+func DeleteQuestion(ctx context.Context, quizID, questionID string) error {
+	// language=SQL
+	_, err := pgctx.Exec(ctx, `
+		delete from quiz_questions
+		where id = $1 and quiz_id = $2
+	`, questionID, quizID)
+	return err
+}
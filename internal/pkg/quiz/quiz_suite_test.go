@@ -0,0 +1,13 @@
+package quiz_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestQuiz(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Quiz Suite")
+}
@@ -0,0 +1,179 @@
+package quiz
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Validate checks is question can be answered
+func (q *Question) Validate() error {
+	if strings.TrimSpace(q.Question) == "" {
+		return ErrInvalidQuestion
+	}
+
+	switch q.Type {
+	case SingleChoice, MultipleChoice:
+		if len(q.Choices) < 2 || len(q.Corrects) == 0 {
+			return ErrInvalidQuestion
+		}
+		if q.Type == SingleChoice && len(q.Corrects) != 1 {
+			return ErrInvalidQuestion
+		}
+		for _, i := range q.Corrects {
+			if i < 0 || i >= int64(len(q.Choices)) {
+				return ErrInvalidQuestion
+			}
+		}
+	case Text:
+		if len(q.Answers) == 0 {
+			return ErrInvalidQuestion
+		}
+		switch q.Match {
+		case Exact, IgnoreCase:
+		case Regex:
+			for _, x := range q.Answers {
+				if _, err := regexp.Compile(x); err != nil {
+					return ErrInvalidQuestion
+				}
+			}
+		default:
+			return ErrInvalidQuestion
+		}
+	default:
+		return ErrInvalidQuestion
+	}
+	return nil
+}
+
+// Check checks is user's answer correct,
+// choice answer is the index of the choice, text answer is the text that user typed
+func (q *Question) Check(answer []string) bool {
+	switch q.Type {
+	case SingleChoice, MultipleChoice:
+		if len(answer) != len(q.Corrects) {
+			return false
+		}
+
+		correct := make(map[int64]bool)
+		for _, i := range q.Corrects {
+			correct[i] = true
+		}
+		for _, a := range answer {
+			i, err := strconv.ParseInt(a, 10, 64)
+			if err != nil || !correct[i] {
+				return false
+			}
+			// prevent duplicate answer
+			delete(correct, i)
+		}
+		return true
+	case Text:
+		if len(answer) != 1 {
+			return false
+		}
+
+		a := strings.TrimSpace(answer[0])
+		for _, x := range q.Answers {
+			switch q.Match {
+			case Exact:
+				// exact match ignores only surrounding spaces
+				if a == strings.TrimSpace(x) {
+					return true
+				}
+			case IgnoreCase:
+				if strings.EqualFold(a, strings.TrimSpace(x)) {
+					return true
+				}
+			case Regex:
+				re, err := regexp.Compile("^(?:" + x + ")$")
+				if err == nil && re.MatchString(a) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// Score scores user's answers, answers is mapped by question id
+func (x *Quiz) Score(answers map[string][]string) (score, total int) {
+	for _, q := range x.Questions {
+		if q.Check(answers[q.ID]) {
+			score++
+		}
+	}
+	return score, len(x.Questions)
+}
+
+// Passed checks is score passes quiz's pass mark
+func (x *Quiz) Passed(score, total int) bool {
+	if total == 0 {
+		return true
+	}
+	return score*100 >= x.PassMark*total
+}
+
+// CanAttempt checks is user who already used attempts can attempt the quiz again
+func (x *Quiz) CanAttempt(used int) bool {
+	return x.MaxAttempts <= 0 || used < x.MaxAttempts
+}
+
+// AttemptsLeft returns number of attempts left for user who already used attempts
+func (x *Quiz) AttemptsLeft(used int) int {
+	if used >= x.MaxAttempts {
+		return 0
+	}
+	return x.MaxAttempts - used
+}
+
+// ParseChoices parses choices from text, one choice per line,
+// correct choices are prefixed with "*"
+func ParseChoices(s string) (choices []string, corrects []int64) {
+	for _, l := range strings.Split(s, "\n") {
+		l = strings.TrimSpace(l)
+		correct := strings.HasPrefix(l, "*")
+		if correct {
+			l = strings.TrimSpace(strings.TrimPrefix(l, "*"))
+		}
+		if l == "" {
+			continue
+		}
+
+		if correct {
+			corrects = append(corrects, int64(len(choices)))
+		}
+		choices = append(choices, l)
+	}
+	return
+}
+
+// ChoicesText formats question's choices back to text which can be parsed by ParseChoices
+func (q *Question) ChoicesText() string {
+	correct := make(map[int64]bool)
+	for _, i := range q.Corrects {
+		correct[i] = true
+	}
+
+	var b strings.Builder
+	for i, x := range q.Choices {
+		if correct[int64(i)] {
+			b.WriteString("* ")
+		}
+		b.WriteString(x)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// ParseAnswers parses accepted text answers, one answer per line
+func ParseAnswers(s string) []string {
+	var xs []string
+	for _, l := range strings.Split(s, "\n") {
+		l = strings.TrimSpace(l)
+		if l != "" {
+			xs = append(xs, l)
+		}
+	}
+	return xs
+}
@@ -0,0 +1,209 @@
+package quiz_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/acoshift/acourse/internal/pkg/quiz"
+)
+
+var _ = Describe("Score", func() {
+	Describe("Check", func() {
+		Context("SingleChoice", func() {
+			q := &Question{
+				Type:     SingleChoice,
+				Question: "1 + 1",
+				Choices:  []string{"1", "2", "3"},
+				Corrects: []int64{1},
+			}
+
+			It("should be correct with correct choice", func() {
+				Expect(q.Check([]string{"1"})).To(BeTrue())
+			})
+
+			It("should be incorrect with wrong choice", func() {
+				Expect(q.Check([]string{"0"})).To(BeFalse())
+			})
+
+			It("should be incorrect without answer", func() {
+				Expect(q.Check(nil)).To(BeFalse())
+			})
+
+			It("should be incorrect with invalid choice", func() {
+				Expect(q.Check([]string{"a"})).To(BeFalse())
+			})
+		})
+
+		Context("MultipleChoice", func() {
+			q := &Question{
+				Type:     MultipleChoice,
+				Question: "even numbers",
+				Choices:  []string{"1", "2", "3", "4"},
+				Corrects: []int64{1, 3},
+			}
+
+			It("should be correct with all correct choices in any order", func() {
+				Expect(q.Check([]string{"3", "1"})).To(BeTrue())
+			})
+
+			It("should be incorrect with some correct choices", func() {
+				Expect(q.Check([]string{"1"})).To(BeFalse())
+			})
+
+			It("should be incorrect with extra choice", func() {
+				Expect(q.Check([]string{"1", "2", "3"})).To(BeFalse())
+			})
+
+			It("should be incorrect with duplicate choice", func() {
+				Expect(q.Check([]string{"1", "1"})).To(BeFalse())
+			})
+		})
+
+		Context("Text", func() {
+			It("should match exact answer ignore spaces", func() {
+				q := &Question{
+					Type:     Text,
+					Question: "language",
+					Answers:  []string{"Go", "golang"},
+					Match:    Exact,
+				}
+
+				Expect(q.Check([]string{" Go "})).To(BeTrue())
+				Expect(q.Check([]string{"golang"})).To(BeTrue())
+				Expect(q.Check([]string{"go"})).To(BeFalse())
+				Expect(q.Check([]string{"gopher"})).To(BeFalse())
+			})
+
+			It("should match exact answer ignore case and spaces", func() {
+				q := &Question{
+					Type:     Text,
+					Question: "language",
+					Answers:  []string{"Go", "golang"},
+					Match:    IgnoreCase,
+				}
+
+				Expect(q.Check([]string{" go "})).To(BeTrue())
+				Expect(q.Check([]string{"GoLang"})).To(BeTrue())
+				Expect(q.Check([]string{"gopher"})).To(BeFalse())
+			})
+
+			It("should match whole answer with regex", func() {
+				q := &Question{
+					Type:     Text,
+					Question: "number",
+					Answers:  []string{`\d+`},
+					Match:    Regex,
+				}
+
+				Expect(q.Check([]string{"123"})).To(BeTrue())
+				Expect(q.Check([]string{"a123"})).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("Validate", func() {
+		It("should success with valid choices", func() {
+			q := &Question{
+				Type:     SingleChoice,
+				Question: "q",
+				Choices:  []string{"a", "b"},
+				Corrects: []int64{0},
+			}
+
+			Expect(q.Validate()).To(Succeed())
+		})
+
+		It("should return error when single choice has many corrects", func() {
+			q := &Question{
+				Type:     SingleChoice,
+				Question: "q",
+				Choices:  []string{"a", "b"},
+				Corrects: []int64{0, 1},
+			}
+
+			Expect(q.Validate()).To(Equal(ErrInvalidQuestion))
+		})
+
+		It("should return error when correct choice out of range", func() {
+			q := &Question{
+				Type:     MultipleChoice,
+				Question: "q",
+				Choices:  []string{"a", "b"},
+				Corrects: []int64{2},
+			}
+
+			Expect(q.Validate()).To(Equal(ErrInvalidQuestion))
+		})
+
+		It("should return error with invalid regex", func() {
+			q := &Question{
+				Type:     Text,
+				Question: "q",
+				Answers:  []string{"("},
+				Match:    Regex,
+			}
+
+			Expect(q.Validate()).To(Equal(ErrInvalidQuestion))
+		})
+
+		It("should return error with unknown match mode", func() {
+			q := &Question{
+				Type:     Text,
+				Question: "q",
+				Answers:  []string{"a"},
+				Match:    -1,
+			}
+
+			Expect(q.Validate()).To(Equal(ErrInvalidQuestion))
+		})
+	})
+
+	Describe("Quiz", func() {
+		x := &Quiz{
+			PassMark:    50,
+			MaxAttempts: 2,
+			Questions: []*Question{
+				{ID: "1", Type: SingleChoice, Choices: []string{"a", "b"}, Corrects: []int64{0}},
+				{ID: "2", Type: Text, Answers: []string{"go"}},
+			},
+		}
+
+		It("should score answers", func() {
+			score, total := x.Score(map[string][]string{
+				"1": {"0"},
+				"2": {"rust"},
+			})
+
+			Expect(score).To(Equal(1))
+			Expect(total).To(Equal(2))
+			Expect(x.Passed(score, total)).To(BeTrue())
+		})
+
+		It("should not pass when score below pass mark", func() {
+			Expect(x.Passed(0, 2)).To(BeFalse())
+		})
+
+		It("should limit attempts", func() {
+			Expect(x.CanAttempt(1)).To(BeTrue())
+			Expect(x.CanAttempt(2)).To(BeFalse())
+			Expect(x.AttemptsLeft(1)).To(Equal(1))
+		})
+	})
+
+	Describe("ParseChoices", func() {
+		It("should parse correct choices", func() {
+			choices, corrects := ParseChoices("* a\nb\n\n*c\n")
+
+			Expect(choices).To(Equal([]string{"a", "b", "c"}))
+			Expect(corrects).To(Equal([]int64{0, 2}))
+		})
+
+		It("should format back to text", func() {
+			q := &Question{Choices: []string{"a", "b"}, Corrects: []int64{1}}
+
+			choices, corrects := ParseChoices(q.ChoicesText())
+			Expect(choices).To(Equal(q.Choices))
+			Expect(corrects).To(Equal(q.Corrects))
+		})
+	})
+})
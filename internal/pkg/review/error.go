@@ -0,0 +1,11 @@
+package review
+
+import (
+	"errors"
+)
+
+var (
+	ErrNotFound      = errors.New("review: not found")
+	ErrNotEnrolled   = errors.New("review: not enrolled")
+	ErrInvalidRating = errors.New("review: invalid rating")
+)
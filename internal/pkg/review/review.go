@@ -0,0 +1,190 @@
+package review
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/acoshift/pgsql"
+	"github.com/acoshift/pgsql/pgctx"
+
+	"github.com/acoshift/acourse/internal/pkg/course"
+)
+
+// Rating range
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+// Review is an enrolled user's rating and review of a course
+type Review struct {
+	CourseID string
+	User     struct {
+		ID       string
+		Username string
+		Name     string
+		Image    string
+	}
+	Rating    int
+	Review    string // markdown
+	Reply     string // course owner's reply in markdown
+	RepliedAt time.Time
+	Hidden    bool
+	Flagged   bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type SaveArgs struct {
+	CourseID string
+	UserID   string
+	Rating   int
+	Review   string
+}
+
+// Save creates or updates user's review of a course, only enrolled user can review
+func Save(ctx context.Context, m *SaveArgs) error {
+	if m.Rating < MinRating || m.Rating > MaxRating {
+		return ErrInvalidRating
+	}
+
+	enrolled, err := course.IsEnroll(ctx, m.UserID, m.CourseID)
+	if err != nil {
+		return err
+	}
+	if !enrolled {
+		return ErrNotEnrolled
+	}
+
+	// language=SQL
+	_, err = pgctx.Exec(ctx, `
+		insert into reviews
+			(course_id, user_id, rating, review)
+		values
+			($1, $2, $3, $4)
+		on conflict (course_id, user_id) do update
+		set
+			rating = excluded.rating,
+			review = excluded.review,
+			updated_at = now()
+	`, m.CourseID, m.UserID, m.Rating, m.Review)
+	return err
+}
+
+// Get gets user's review of a course
+func Get(ctx context.Context, courseID, userID string) (*Review, error) {
+	var x Review
+	// language=SQL
+	err := pgctx.QueryRow(ctx, `
+		select
+			r.course_id, r.rating, r.review, r.reply, r.replied_at,
+			r.hidden, r.flagged, r.created_at, r.updated_at,
+			u.id, u.username, u.name, u.image
+		from reviews as r
+			left join users as u on u.id = r.user_id
+		where r.course_id = $1 and r.user_id = $2
+	`, courseID, userID).Scan(
+		&x.CourseID, &x.Rating, &x.Review, &x.Reply, pgsql.NullTime(&x.RepliedAt),
+		&x.Hidden, &x.Flagged, &x.CreatedAt, &x.UpdatedAt,
+		&x.User.ID, &x.User.Username, &x.User.Name, &x.User.Image,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &x, nil
+}
+
+// Count counts visible reviews of a course
+func Count(ctx context.Context, courseID string) (cnt int64, err error) {
+	// language=SQL
+	err = pgctx.QueryRow(ctx, `
+		select count(*)
+		from reviews
+		where course_id = $1 and not hidden
+	`, courseID).Scan(&cnt)
+	return
+}
+
+// List lists visible reviews of a course, newest first
+func List(ctx context.Context, courseID string, limit, offset int64) ([]*Review, error) {
+	// language=SQL
+	rows, err := pgctx.Query(ctx, `
+		select
+			r.course_id, r.rating, r.review, r.reply, r.replied_at,
+			r.hidden, r.flagged, r.created_at, r.updated_at,
+			u.id, u.username, u.name, u.image
+		from reviews as r
+			left join users as u on u.id = r.user_id
+		where r.course_id = $1 and not r.hidden
+		order by r.created_at desc
+		limit $2 offset $3
+	`, courseID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var xs []*Review
+	for rows.Next() {
+		var x Review
+		err = rows.Scan(
+			&x.CourseID, &x.Rating, &x.Review, &x.Reply, pgsql.NullTime(&x.RepliedAt),
+			&x.Hidden, &x.Flagged, &x.CreatedAt, &x.UpdatedAt,
+			&x.User.ID, &x.User.Username, &x.User.Name, &x.User.Image,
+		)
+		if err != nil {
+			return nil, err
+		}
+		xs = append(xs, &x)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return xs, nil
+}
+
+// Reply sets course owner's reply to user's review, empty reply removes the reply
+func Reply(ctx context.Context, courseID, userID, reply string) error {
+	// language=SQL
+	res, err := pgctx.Exec(ctx, `
+		update reviews
+		set
+			reply = $3,
+			replied_at = case when $3 = '' then null else now() end
+		where course_id = $1 and user_id = $2
+	`, courseID, userID, reply)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// SetFlagged flags review for admin moderation
+func SetFlagged(ctx context.Context, courseID, userID string, flagged bool) error {
+	// language=SQL
+	_, err := pgctx.Exec(ctx, `
+		update reviews
+		set flagged = $3
+		where course_id = $1 and user_id = $2
+	`, courseID, userID, flagged)
+	return err
+}
+
+// SetHidden hides review from course page and course's rating
+func SetHidden(ctx context.Context, courseID, userID string, hidden bool) error {
+	// language=SQL
+	_, err := pgctx.Exec(ctx, `
+		update reviews
+		set hidden = $3
+		where course_id = $1 and user_id = $2
+	`, courseID, userID, hidden)
+	return err
+}
@@ -0,0 +1,86 @@
+package search
+
+import (
+	"html/template"
+	"strings"
+	"unicode/utf8"
+)
+
+// Terms splits search query into terms
+func Terms(q string) []string {
+	return strings.Fields(strings.ToLower(q))
+}
+
+// find finds first occurrence of any term in s, returns byte index and length of matched term,
+// index is -1 if not found
+func find(s string, terms []string) (int, int) {
+	lower := strings.ToLower(s)
+
+	// lower case may change byte length, fallback to not found
+	if len(lower) != len(s) {
+		return -1, 0
+	}
+
+	idx, n := -1, 0
+	for _, t := range terms {
+		i := strings.Index(lower, t)
+		if i < 0 {
+			continue
+		}
+		if idx < 0 || i < idx || (i == idx && len(t) > n) {
+			idx, n = i, len(t)
+		}
+	}
+	return idx, n
+}
+
+// Highlight escapes s then marks all terms of q found in s
+func Highlight(s string, q string) template.HTML {
+	terms := Terms(q)
+
+	var b strings.Builder
+	for {
+		i, n := find(s, terms)
+		if i < 0 || n == 0 {
+			b.WriteString(template.HTMLEscapeString(s))
+			break
+		}
+		b.WriteString(template.HTMLEscapeString(s[:i]))
+		b.WriteString("<mark>")
+		b.WriteString(template.HTMLEscapeString(s[i : i+n]))
+		b.WriteString("</mark>")
+		s = s[i+n:]
+	}
+	return template.HTML(b.String())
+}
+
+// Snippet returns part of s around the first matched term of q, not longer than size runes,
+// returns empty string if s does not contain any term
+func Snippet(s string, q string, size int) string {
+	i, _ := find(s, Terms(q))
+	if i < 0 {
+		return ""
+	}
+
+	// move start back to show text before matched term
+	start := i
+	for k := 0; k < size/4 && start > 0; k++ {
+		_, w := utf8.DecodeLastRuneInString(s[:start])
+		start -= w
+	}
+
+	end := start
+	for k := 0; k < size && end < len(s); k++ {
+		_, w := utf8.DecodeRuneInString(s[end:])
+		end += w
+	}
+
+	r := strings.Join(strings.Fields(s[start:end]), " ")
+	if start > 0 {
+		r = "…" + r
+	}
+	if end < len(s) {
+		r += "…"
+	}
+	return r
+}
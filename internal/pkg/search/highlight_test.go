@@ -0,0 +1,62 @@
+package search_test
+
+import (
+	"html/template"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/acoshift/acourse/internal/pkg/search"
+)
+
+var _ = Describe("Highlight", func() {
+	Describe("Terms", func() {
+		It("should split query into lower case terms", func() {
+			Expect(Terms("  Go   Basic ")).To(Equal([]string{"go", "basic"}))
+		})
+
+		It("should return empty for empty query", func() {
+			Expect(Terms(" ")).To(BeEmpty())
+		})
+	})
+
+	Describe("Highlight", func() {
+		It("should mark all terms ignoring case", func() {
+			Expect(Highlight("Go and go", "go")).To(Equal(template.HTML("<mark>Go</mark> and <mark>go</mark>")))
+		})
+
+		It("should mark many terms", func() {
+			Expect(Highlight("Learn Go Basic", "basic go")).To(Equal(template.HTML("Learn <mark>Go</mark> <mark>Basic</mark>")))
+		})
+
+		It("should mark thai text", func() {
+			Expect(Highlight("เรียนภาษาโกเบื้องต้น", "ภาษาโก")).To(Equal(template.HTML("เรียน<mark>ภาษาโก</mark>เบื้องต้น")))
+		})
+
+		It("should escape html", func() {
+			Expect(Highlight("<b>go</b>", "go")).To(Equal(template.HTML("&lt;b&gt;<mark>go</mark>&lt;/b&gt;")))
+		})
+
+		It("should not mark when query is empty", func() {
+			Expect(Highlight("go", "")).To(Equal(template.HTML("go")))
+		})
+	})
+
+	Describe("Snippet", func() {
+		It("should return empty when not match", func() {
+			Expect(Snippet("learn go", "rust", 10)).To(BeEmpty())
+		})
+
+		It("should return whole text when shorter than size", func() {
+			Expect(Snippet("learn go", "go", 100)).To(Equal("learn go"))
+		})
+
+		It("should cut text around matched term", func() {
+			Expect(Snippet("aaaaaaaa go bbbbbbbb", "go", 8)).To(Equal("…a go bbb…"))
+		})
+
+		It("should cut thai text by rune", func() {
+			Expect(Snippet("เรียนภาษาโกเบื้องต้น", "ภาษา", 8)).To(Equal("…ยนภาษาโก…"))
+		})
+	})
+})
@@ -0,0 +1,138 @@
+package search
+
+import (
+	"context"
+	"strings"
+
+	"github.com/acoshift/pgsql"
+	"github.com/acoshift/pgsql/pgctx"
+
+	"github.com/acoshift/acourse/internal/pkg/course"
+)
+
+// snippetSize is the maximum length of result's snippet in runes
+const snippetSize = 160
+
+// Course is a search result
+type Course struct {
+	*course.PublicCard
+
+	// Snippet is a part of course's text which matched the query
+	Snippet string
+}
+
+// searchQuery selects matched public courses with rank from the indexed search columns,
+// $1 is normalized query and $2 is like pattern of the query
+//
+// language=SQL
+const searchQuery = `
+	with matches as (
+		select
+			c.id, c.created_at,
+			ts_rank(c.search_doc, plainto_tsquery('simple', $1)) +
+			word_similarity($1, lower(c.title)) * 2 +
+			word_similarity($1, c.search_text) as rank
+		from courses as c
+			inner join course_options as opt on opt.course_id = c.id
+		where opt.public and c.archived_at is null
+		  and (
+			c.search_doc @@ plainto_tsquery('simple', $1)
+			or c.search_text like $2
+			or $1 <% c.search_text
+		  )
+	)
+`
+
+// CountCourses counts public courses which match q
+func CountCourses(ctx context.Context, q string) (cnt int64, err error) {
+	q = normalize(q)
+	if q == "" {
+		return 0, nil
+	}
+
+	err = pgctx.QueryRow(ctx, searchQuery+`
+		select count(*) from matches
+	`, q, likePattern(q)).Scan(&cnt)
+	return
+}
+
+// Courses searches public courses by title, short description, description and content titles,
+// results are ordered by rank
+func Courses(ctx context.Context, q string, limit, offset int64) ([]*Course, error) {
+	q = normalize(q)
+	if q == "" {
+		return nil, nil
+	}
+
+	rows, err := pgctx.Query(ctx, searchQuery+`
+		select
+			c.id, c.title, c.short_desc, c.image, c.start, c.url,
+			c.type, c.price, c.discount,
+			opt.public, opt.enroll, opt.attend, opt.assignment, opt.discount,
+			coalesce((select avg(rating) from reviews where course_id = c.id and not hidden), 0),
+			(select count(*) from reviews where course_id = c.id and not hidden),
+			c.long_desc,
+			coalesce((select string_agg(title, ' ' order by i) from course_contents where course_id = c.id), '')
+		from (
+			select id, rank, created_at
+			from matches
+			order by rank desc, created_at desc
+			limit $3 offset $4
+		) as m
+			inner join courses as c on c.id = m.id
+			inner join course_options as opt on opt.course_id = c.id
+		order by m.rank desc, m.created_at desc
+	`, q, likePattern(q), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var xs []*Course
+	for rows.Next() {
+		var (
+			x             course.PublicCard
+			desc          string
+			contentTitles string
+		)
+		err = rows.Scan(
+			&x.ID, &x.Title, &x.Desc, &x.Image, pgsql.NullTime(&x.Start), pgsql.NullString(&x.URL),
+			&x.Type, &x.Price, &x.Discount,
+			&x.Option.Public, &x.Option.Enroll, &x.Option.Attend, &x.Option.Assignment, &x.Option.Discount,
+			&x.Rating.Average, &x.Rating.Count,
+			&desc, &contentTitles,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		r := Course{PublicCard: &x}
+		for _, s := range []string{x.Desc, contentTitles, desc} {
+			r.Snippet = Snippet(s, q, snippetSize)
+			if r.Snippet != "" {
+				break
+			}
+		}
+		if r.Snippet == "" {
+			r.Snippet = x.Desc
+		}
+		xs = append(xs, &r)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return xs, nil
+}
+
+// normalize lowers case and removes extra spaces from q
+func normalize(q string) string {
+	return strings.Join(Terms(q), " ")
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// likePattern returns like pattern which matches text contains q
+func likePattern(q string) string {
+	return "%" + likeEscaper.Replace(q) + "%"
+}
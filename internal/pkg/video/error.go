@@ -0,0 +1,11 @@
+package video
+
+import (
+	"errors"
+)
+
+var (
+	ErrInvalidID       = errors.New("video: invalid id")
+	ErrInvalidType     = errors.New("video: invalid type")
+	ErrUnknownProvider = errors.New("video: unknown provider")
+)
@@ -0,0 +1,98 @@
+package video
+
+import (
+	"html/template"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/acoshift/acourse/internal/pkg/file"
+)
+
+var allowURLExt = map[string]bool{
+	".mp4":  true,
+	".webm": true,
+	".m3u8": true,
+}
+
+var urlEmbed = template.Must(template.New("").Parse(`
+<video width="560" height="315" controls playsinline preload="metadata" {{if .HLS}}data-hls="{{.Src}}"{{else}}src="{{.Src}}"{{end}}></video>
+{{if .HLS}}
+<script src="https://cdn.jsdelivr.net/npm/hls.js@1"></script>
+<script>
+	document.querySelectorAll('video[data-hls]').forEach((v) => {
+		const src = v.dataset.hls
+		if (v.canPlayType('application/vnd.apple.mpegurl')) {
+			v.src = src
+		} else if (window.Hls && window.Hls.isSupported()) {
+			const hls = new window.Hls()
+			hls.loadSource(src)
+			hls.attachMedia(v)
+		}
+	})
+</script>
+{{end}}
+`))
+
+func parseVideoURL(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", ErrInvalidID
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return "", ErrInvalidID
+	}
+	if u.Host == "" {
+		return "", ErrInvalidID
+	}
+	if !allowURLExt[strings.ToLower(path.Ext(u.Path))] {
+		return "", ErrInvalidID
+	}
+	return u.String(), nil
+}
+
+func embedVideoURL(src string) template.HTML {
+	u, _ := url.Parse(src)
+	return render(urlEmbed, map[string]interface{}{
+		"Src": src,
+		"HLS": u != nil && strings.ToLower(path.Ext(u.Path)) == ".m3u8",
+	})
+}
+
+// urlProvider plays HLS or MP4 video from any url
+type urlProvider struct{}
+
+func (urlProvider) Name() string {
+	return "HLS/MP4 URL"
+}
+
+func (urlProvider) Parse(s string) (string, error) {
+	return parseVideoURL(s)
+}
+
+func (urlProvider) Embed(id string) template.HTML {
+	return embedVideoURL(id)
+}
+
+// fileProvider plays video uploaded to our storage
+type fileProvider struct{}
+
+func (fileProvider) Name() string {
+	return "อัพโหลดไฟล์"
+}
+
+func (fileProvider) Parse(s string) (string, error) {
+	id, err := parseVideoURL(s)
+	if err != nil {
+		return "", err
+	}
+	if !file.IsStoreURL(id) {
+		return "", ErrInvalidID
+	}
+	return id, nil
+}
+
+func (fileProvider) Embed(id string) template.HTML {
+	return embedVideoURL(id)
+}
@@ -0,0 +1,27 @@
+package video
+
+import (
+	"mime"
+	"mime/multipart"
+
+	"github.com/acoshift/header"
+)
+
+var allowFileType = map[string]string{
+	"video/mp4":  ".mp4",
+	"video/webm": ".webm",
+}
+
+// FileExt returns file extension for video file header
+func FileExt(fh *multipart.FileHeader) (string, error) {
+	if fh == nil || fh.Header == nil {
+		return "", ErrInvalidType
+	}
+
+	ct, _, _ := mime.ParseMediaType(fh.Header.Get(header.ContentType))
+	ext, ok := allowFileType[ct]
+	if !ok {
+		return "", ErrInvalidType
+	}
+	return ext, nil
+}
@@ -0,0 +1,106 @@
+package video
+
+import (
+	"bytes"
+	"html/template"
+	"sort"
+)
+
+// Type values
+const (
+	_ = iota
+	Youtube
+	Vimeo
+	URL
+	File
+)
+
+// Provider is a video provider
+type Provider interface {
+	// Name returns provider's display name
+	Name() string
+
+	// Parse parses pasted url or id into provider's canonical id
+	Parse(s string) (string, error)
+
+	// Embed renders player for the canonical id
+	Embed(id string) template.HTML
+}
+
+// Info is a registered provider info
+type Info struct {
+	Type int
+	Name string
+}
+
+var providers = map[int]Provider{}
+
+// Register registers a provider for video type
+func Register(typ int, p Provider) {
+	providers[typ] = p
+}
+
+// Get gets provider for video type
+func Get(typ int) (Provider, error) {
+	p, ok := providers[typ]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return p, nil
+}
+
+// Providers returns all registered providers sorted by type
+func Providers() []*Info {
+	xs := make([]*Info, 0, len(providers))
+	for typ, p := range providers {
+		xs = append(xs, &Info{Type: typ, Name: p.Name()})
+	}
+	sort.Slice(xs, func(i, j int) bool { return xs[i].Type < xs[j].Type })
+	return xs
+}
+
+// Parse parses pasted url or id using video type's provider
+func Parse(typ int, s string) (string, error) {
+	p, err := Get(typ)
+	if err != nil {
+		return "", err
+	}
+	return p.Parse(s)
+}
+
+// Embed renders player for video, returns empty if video type is unknown
+func Embed(typ int, id string) template.HTML {
+	if id == "" {
+		return ""
+	}
+	p, err := Get(typ)
+	if err != nil {
+		return ""
+	}
+	return p.Embed(id)
+}
+
+// Name returns video type's provider name
+func Name(typ int) string {
+	p, err := Get(typ)
+	if err != nil {
+		return ""
+	}
+	return p.Name()
+}
+
+func render(t *template.Template, data interface{}) template.HTML {
+	var buf bytes.Buffer
+	err := t.Execute(&buf, data)
+	if err != nil {
+		return ""
+	}
+	return template.HTML(buf.String())
+}
+
+func init() {
+	Register(Youtube, youtubeProvider{})
+	Register(Vimeo, vimeoProvider{})
+	Register(URL, urlProvider{})
+	Register(File, fileProvider{})
+}
@@ -0,0 +1,150 @@
+package video_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/acoshift/acourse/internal/pkg/video"
+)
+
+var _ = Describe("Video", func() {
+	Describe("Parse", func() {
+		It("should return error when provider is unknown", func() {
+			id, err := Parse(0, "dQw4w9WgXcQ")
+
+			Expect(err).To(Equal(ErrUnknownProvider))
+			Expect(id).To(BeZero())
+		})
+
+		Context("Youtube", func() {
+			It("should success with id", func() {
+				id, err := Parse(Youtube, " dQw4w9WgXcQ ")
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(id).To(Equal("dQw4w9WgXcQ"))
+			})
+
+			It("should success with watch url", func() {
+				id, err := Parse(Youtube, "https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=10s")
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(id).To(Equal("dQw4w9WgXcQ"))
+			})
+
+			It("should success with short url", func() {
+				id, err := Parse(Youtube, "https://youtu.be/dQw4w9WgXcQ")
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(id).To(Equal("dQw4w9WgXcQ"))
+			})
+
+			It("should success with embed url", func() {
+				id, err := Parse(Youtube, "https://www.youtube.com/embed/dQw4w9WgXcQ")
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(id).To(Equal("dQw4w9WgXcQ"))
+			})
+
+			It("should return error with other host", func() {
+				id, err := Parse(Youtube, "https://example.com/watch?v=dQw4w9WgXcQ")
+
+				Expect(err).To(Equal(ErrInvalidID))
+				Expect(id).To(BeZero())
+			})
+
+			It("should return error with invalid id", func() {
+				id, err := Parse(Youtube, "https://www.youtube.com/watch?v=<script>")
+
+				Expect(err).To(Equal(ErrInvalidID))
+				Expect(id).To(BeZero())
+			})
+		})
+
+		Context("Vimeo", func() {
+			It("should success with id", func() {
+				id, err := Parse(Vimeo, "76979871")
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(id).To(Equal("76979871"))
+			})
+
+			It("should success with url", func() {
+				id, err := Parse(Vimeo, "https://vimeo.com/channels/staffpicks/76979871")
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(id).To(Equal("76979871"))
+			})
+
+			It("should success with player url", func() {
+				id, err := Parse(Vimeo, "https://player.vimeo.com/video/76979871")
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(id).To(Equal("76979871"))
+			})
+
+			It("should return error with youtube url", func() {
+				id, err := Parse(Vimeo, "https://youtu.be/dQw4w9WgXcQ")
+
+				Expect(err).To(Equal(ErrInvalidID))
+				Expect(id).To(BeZero())
+			})
+		})
+
+		Context("URL", func() {
+			It("should success with mp4", func() {
+				id, err := Parse(URL, "https://cdn.example.com/video.mp4")
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(id).To(Equal("https://cdn.example.com/video.mp4"))
+			})
+
+			It("should success with hls", func() {
+				id, err := Parse(URL, "https://cdn.example.com/live/index.m3u8?token=1")
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(id).To(Equal("https://cdn.example.com/live/index.m3u8?token=1"))
+			})
+
+			It("should return error when not video", func() {
+				id, err := Parse(URL, "https://cdn.example.com/video.html")
+
+				Expect(err).To(Equal(ErrInvalidID))
+				Expect(id).To(BeZero())
+			})
+
+			It("should return error when not http", func() {
+				id, err := Parse(URL, "javascript:alert(1)//.mp4")
+
+				Expect(err).To(Equal(ErrInvalidID))
+				Expect(id).To(BeZero())
+			})
+		})
+
+		Context("File", func() {
+			It("should return error when url is not from storage", func() {
+				id, err := Parse(File, "https://cdn.example.com/video.mp4")
+
+				Expect(err).To(Equal(ErrInvalidID))
+				Expect(id).To(BeZero())
+			})
+		})
+	})
+
+	Describe("Embed", func() {
+		It("should return empty when id is empty", func() {
+			Expect(Embed(Youtube, "")).To(BeZero())
+		})
+
+		It("should return empty when provider is unknown", func() {
+			Expect(Embed(0, "dQw4w9WgXcQ")).To(BeZero())
+		})
+
+		It("should render youtube player", func() {
+			Expect(string(Embed(Youtube, "dQw4w9WgXcQ"))).To(ContainSubstring("https://www.youtube.com/embed/dQw4w9WgXcQ"))
+		})
+
+		It("should render hls player", func() {
+			Expect(string(Embed(URL, "https://cdn.example.com/index.m3u8"))).To(ContainSubstring(`data-hls="https://cdn.example.com/index.m3u8"`))
+		})
+	})
+})
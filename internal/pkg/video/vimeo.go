@@ -0,0 +1,55 @@
+package video
+
+import (
+	"html/template"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var vimeoID = regexp.MustCompile(`^[0-9]+$`)
+
+var vimeoEmbed = template.Must(template.New("").Parse(`
+<iframe width="560" height="315"
+		frameborder="0" scrolling="no"
+		allow="fullscreen; picture-in-picture"
+		allowfullscreen
+		src="https://player.vimeo.com/video/{{.}}">
+</iframe>
+`))
+
+type vimeoProvider struct{}
+
+func (vimeoProvider) Name() string {
+	return "Vimeo"
+}
+
+func (vimeoProvider) Parse(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if vimeoID.MatchString(s) {
+		return s, nil
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", ErrInvalidID
+	}
+
+	var id string
+	switch strings.TrimPrefix(u.Hostname(), "www.") {
+	case "vimeo.com":
+		// last numeric path segment, e.g. /123456 or /channels/staffpicks/123456
+		ps := strings.Split(strings.Trim(u.Path, "/"), "/")
+		id = ps[len(ps)-1]
+	case "player.vimeo.com":
+		id = strings.TrimPrefix(u.Path, "/video/")
+	}
+	if !vimeoID.MatchString(id) {
+		return "", ErrInvalidID
+	}
+	return id, nil
+}
+
+func (vimeoProvider) Embed(id string) template.HTML {
+	return render(vimeoEmbed, id)
+}
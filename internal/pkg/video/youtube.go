@@ -0,0 +1,61 @@
+package video
+
+import (
+	"html/template"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var youtubeID = regexp.MustCompile(`^[a-zA-Z0-9_-]{11}$`)
+
+var youtubeEmbed = template.Must(template.New("").Parse(`
+<iframe width="560" height="315"
+		frameborder="0" scrolling="no"
+		allowfullscreen
+		src="https://www.youtube.com/embed/{{.}}?rel=0&hd=1">
+</iframe>
+`))
+
+type youtubeProvider struct{}
+
+func (youtubeProvider) Name() string {
+	return "YouTube"
+}
+
+func (youtubeProvider) Parse(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if youtubeID.MatchString(s) {
+		return s, nil
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", ErrInvalidID
+	}
+
+	var id string
+	switch strings.TrimPrefix(u.Hostname(), "www.") {
+	case "youtu.be":
+		id = strings.Trim(u.Path, "/")
+	case "youtube.com", "m.youtube.com", "youtube-nocookie.com":
+		switch {
+		case u.Path == "/watch":
+			id = u.Query().Get("v")
+		case strings.HasPrefix(u.Path, "/embed/"):
+			id = strings.TrimPrefix(u.Path, "/embed/")
+		case strings.HasPrefix(u.Path, "/shorts/"):
+			id = strings.TrimPrefix(u.Path, "/shorts/")
+		case strings.HasPrefix(u.Path, "/live/"):
+			id = strings.TrimPrefix(u.Path, "/live/")
+		}
+	}
+	if !youtubeID.MatchString(id) {
+		return "", ErrInvalidID
+	}
+	return id, nil
+}
+
+func (youtubeProvider) Embed(id string) template.HTML {
+	return render(youtubeEmbed, id)
+}
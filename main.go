@@ -11,6 +11,7 @@ import (
 
 	"github.com/acoshift/acourse/internal/app"
 	"github.com/acoshift/acourse/internal/pkg/auth"
+	"github.com/acoshift/acourse/internal/pkg/certificate"
 	"github.com/acoshift/acourse/internal/pkg/config"
 	"github.com/acoshift/acourse/internal/pkg/email"
 	"github.com/acoshift/acourse/internal/pkg/file"
@@ -37,6 +38,7 @@ func main() {
 	defer config.Close()
 
 	auth.Init()
+	certificate.Init()
 	email.Init()
 	file.Init()
 	notify.Init()
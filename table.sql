@@ -1,4 +1,5 @@
 -- create extension if not exists pgcrypto;
+create extension if not exists pg_trgm;
 
 create table sessions (
     id         varchar,
@@ -36,6 +37,17 @@ create table roles (
 create index on roles (admin);
 create index on roles (instructor);
 
+create table categories (
+	id uuid default gen_random_uuid(),
+	slug varchar not null,
+	name varchar not null,
+	i int not null default 0,
+	created_at timestamp not null default now(),
+	primary key (id)
+);
+create unique index on categories (slug);
+create index on categories (i);
+
 create table courses (
 	id uuid default gen_random_uuid(),
 	user_id varchar not null,
@@ -49,14 +61,61 @@ create table courses (
 	price decimal(9,2) not null default 0,
 	discount decimal(9,2) default 0,
 	enroll_detail varchar not null default '',
+	archived_at timestamp default null,
+	category_id uuid default null,
+	search_doc tsvector not null default ''::tsvector,
+	search_text varchar not null default '',
 	created_at timestamp not null default now(),
 	updated_at timestamp not null default now(),
 	primary key (id),
-	foreign key (user_id) references users (id)
+	foreign key (user_id) references users (id),
+	foreign key (category_id) references categories (id)
 );
 create unique index on courses (url);
+create index on courses using gin (search_doc);
+create index on courses using gin (search_text gin_trgm_ops);
 create index on courses (created_at desc);
 create index on courses (updated_at desc);
+create index on courses (category_id);
+
+create table course_url_history (
+	url varchar not null,
+	course_id uuid not null,
+	created_at timestamp not null default now(),
+	primary key (url),
+	foreign key (course_id) references courses (id)
+);
+create index on course_url_history (course_id);
+
+create table course_tags (
+	course_id uuid not null,
+	tag varchar not null,
+	primary key (course_id, tag),
+	foreign key (course_id) references courses (id)
+);
+create index on course_tags (tag);
+
+create table course_members (
+	course_id uuid not null,
+	user_id varchar not null,
+	role int not null,
+	created_at timestamp not null default now(),
+	primary key (course_id, user_id),
+	foreign key (course_id) references courses (id),
+	foreign key (user_id) references users (id)
+);
+create index on course_members (user_id);
+
+create table course_prerequisites (
+	course_id uuid not null,
+	prerequisite_id uuid not null,
+	required bool not null default false,
+	created_at timestamp not null default now(),
+	primary key (course_id, prerequisite_id),
+	foreign key (course_id) references courses (id),
+	foreign key (prerequisite_id) references courses (id)
+);
+create index on course_prerequisites (prerequisite_id);
 
 create table course_options (
 	course_id uuid,
@@ -74,21 +133,123 @@ create index on course_options (public, enroll);
 create index on course_options (public, discount);
 create index on course_options (public, discount, enroll);
 
+create table course_sections (
+	id uuid default gen_random_uuid(),
+	course_id uuid not null,
+	i int not null default 0,
+	title varchar not null default '',
+	long_desc varchar not null default '',
+	created_at timestamp not null default now(),
+	updated_at timestamp not null default now(),
+	primary key (id),
+	foreign key (course_id) references courses (id)
+);
+create index on course_sections (course_id, i);
+
 create table course_contents (
 	id uuid default gen_random_uuid(),
 	course_id uuid not null,
+	section_id uuid default null,
 	i int not null default 0,
 	title varchar not null default '',
 	long_desc varchar not null default '',
 	video_id varchar not null default '',
 	video_type int not null default 0,
 	download_url varchar not null default '',
+	release int not null default 0,
+	release_days int not null default 0,
+	release_notify bool not null default false,
 	created_at timestamp not null default now(),
 	updated_at timestamp not null default now(),
 	primary key (id),
-	foreign key (course_id) references courses (id)
+	foreign key (course_id) references courses (id),
+	foreign key (section_id) references course_sections (id)
 );
 create index on course_contents (course_id, i);
+create index on course_contents (section_id);
+
+create table content_release_notifies (
+	content_id uuid not null,
+	user_id varchar not null,
+	created_at timestamp not null default now(),
+	primary key (content_id, user_id),
+	foreign key (content_id) references course_contents (id),
+	foreign key (user_id) references users (id)
+);
+
+create table course_content_attachments (
+	id uuid default gen_random_uuid(),
+	content_id uuid not null,
+	i int not null default 0,
+	filename varchar not null,
+	size bigint not null default 0,
+	download_url varchar not null,
+	created_at timestamp not null default now(),
+	primary key (id),
+	foreign key (content_id) references course_contents (id)
+);
+create index on course_content_attachments (content_id, i);
+
+create table content_comments (
+	id uuid default gen_random_uuid(),
+	content_id uuid not null,
+	user_id varchar not null,
+	parent_id uuid default null,
+	body varchar not null,
+	created_at timestamp not null default now(),
+	updated_at timestamp not null default now(),
+	primary key (id),
+	foreign key (content_id) references course_contents (id),
+	foreign key (user_id) references users (id),
+	foreign key (parent_id) references content_comments (id)
+);
+create index on content_comments (content_id, created_at);
+create index on content_comments (parent_id);
+
+create table quizzes (
+	id uuid default gen_random_uuid(),
+	content_id uuid not null,
+	title varchar not null default '',
+	pass_mark int not null default 0,
+	max_attempts int not null default 0,
+	created_at timestamp not null default now(),
+	updated_at timestamp not null default now(),
+	primary key (id),
+	foreign key (content_id) references course_contents (id)
+);
+create unique index on quizzes (content_id);
+
+create table quiz_questions (
+	id uuid default gen_random_uuid(),
+	quiz_id uuid not null,
+	i int not null default 0,
+	type int not null,
+	question varchar not null,
+	choices varchar[] not null default '{}',
+	corrects int[] not null default '{}',
+	answers varchar[] not null default '{}',
+	match int not null default 0,
+	created_at timestamp not null default now(),
+	updated_at timestamp not null default now(),
+	primary key (id),
+	foreign key (quiz_id) references quizzes (id)
+);
+create index on quiz_questions (quiz_id, i);
+
+create table quiz_attempts (
+	id uuid default gen_random_uuid(),
+	user_id varchar not null,
+	quiz_id uuid not null,
+	answers jsonb not null default '{}',
+	score int not null,
+	total int not null,
+	passed bool not null,
+	created_at timestamp not null default now(),
+	primary key (id),
+	foreign key (user_id) references users (id),
+	foreign key (quiz_id) references quizzes (id)
+);
+create index on quiz_attempts (user_id, quiz_id, created_at desc);
 
 create table assignments (
 	id uuid default gen_random_uuid(),
@@ -97,6 +258,10 @@ create table assignments (
 	title varchar not null,
 	long_desc varchar not null,
 	open bool not null default false,
+	required bool not null default true,
+	due_at timestamp default null,
+	due_days int not null default 0,
+	late_days int not null default 0,
 	created_at timestamp not null default now(),
 	updated_at timestamp not null default now(),
 	primary key (id),
@@ -108,13 +273,21 @@ create table user_assignments (
 	id uuid default gen_random_uuid(),
 	user_id varchar not null,
 	assignment_id uuid not null,
+	filename varchar not null default '',
 	download_url varchar not null,
+	answer varchar not null default '',
+	score int not null default 0,
+	passed bool not null default false,
+	feedback varchar not null default '',
+	late bool not null default false,
 	created_at timestamp not null default now(),
+	graded_at timestamp default null,
 	primary key (id),
 	foreign key (user_id) references users (id),
 	foreign key (assignment_id) references assignments (id)
 );
 create index on user_assignments (created_at);
+create index on user_assignments (user_id, assignment_id, created_at desc);
 
 create table enrolls (
 	user_id varchar,
@@ -128,6 +301,34 @@ create index on enrolls (created_at);
 create index on enrolls (user_id, created_at);
 create index on enrolls (course_id, created_at);
 
+create table progresses (
+	user_id varchar not null,
+	course_id uuid not null,
+	content_id uuid not null,
+	completed_at timestamp default null,
+	viewed_at timestamp not null default now(),
+	created_at timestamp not null default now(),
+	primary key (user_id, content_id),
+	foreign key (user_id) references users (id),
+	foreign key (course_id) references courses (id),
+	foreign key (content_id) references course_contents (id)
+);
+create index on progresses (user_id, course_id, viewed_at desc);
+create index on progresses (user_id, course_id, completed_at);
+
+create table certificates (
+	id uuid default gen_random_uuid(),
+	code varchar not null,
+	user_id varchar not null,
+	course_id uuid not null,
+	created_at timestamp not null default now(),
+	primary key (id),
+	foreign key (user_id) references users (id),
+	foreign key (course_id) references courses (id)
+);
+create unique index on certificates (code);
+create unique index on certificates (user_id, course_id);
+
 create table attends (
 	id uuid default gen_random_uuid(),
 	user_id varchar not null,
@@ -162,3 +363,21 @@ create index on payments (created_at desc);
 create index on payments (code);
 create index on payments (course_id, code);
 create index on payments (status, created_at desc);
+
+create table reviews (
+	course_id uuid not null,
+	user_id varchar not null,
+	rating int not null,
+	review varchar not null default '',
+	reply varchar not null default '',
+	replied_at timestamp default null,
+	hidden bool not null default false,
+	flagged bool not null default false,
+	created_at timestamp not null default now(),
+	updated_at timestamp not null default now(),
+	primary key (course_id, user_id),
+	foreign key (course_id) references courses (id),
+	foreign key (user_id) references users (id)
+);
+create index on reviews (course_id, hidden, created_at desc);
+create index on reviews (flagged, created_at desc);